@@ -0,0 +1,104 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/prometheus/node_exporter/collector"
+)
+
+// runCollect runs the enabled collectors, or the ones named in filters, once
+// and writes their metrics to w in the given format, "text" or "json", with
+// the target labels of cliLabels and the configuration added. It returns an
+// error if a collector failed, after writing the metrics of all others.
+func runCollect(w io.Writer, format, configFile string, cliLabels labelsConfig, filters []string, logger *slog.Logger) error {
+	cfg := &config{}
+	if configFile != "" {
+		var err error
+		if cfg, err = loadConfig(configFile); err != nil {
+			return err
+		}
+		if err := collector.ApplyConfig(cfg.Collectors, cfg.MetricRelabelConfigs); err != nil {
+			return err
+		}
+	}
+	labels, err := targetLabels(cliLabels, cfg.labelsConfig)
+	if err != nil {
+		return err
+	}
+	nc, err := collector.NewNodeCollector(logger, filters...)
+	if err != nil {
+		return fmt.Errorf("couldn't create collector: %w", err)
+	}
+	defer nc.Close()
+
+	r := prometheus.NewRegistry()
+	if err := prometheus.WrapRegistererWith(labels, r).Register(nc); err != nil {
+		return fmt.Errorf("couldn't register node collector: %w", err)
+	}
+	families, gatherErr := r.Gather()
+
+	switch format {
+	case "json":
+		messages := make([]json.RawMessage, 0, len(families))
+		for _, mf := range families {
+			b, err := protojson.Marshal(mf)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, b)
+		}
+		b, err := json.MarshalIndent(messages, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", b); err != nil {
+			return err
+		}
+	default:
+		enc := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+		for _, mf := range families {
+			if err := enc.Encode(mf); err != nil {
+				return err
+			}
+		}
+	}
+	if gatherErr != nil {
+		return gatherErr
+	}
+
+	// Collectors finding nothing to collect, e.g. hardware the host doesn't
+	// have, didn't fail.
+	var failed []string
+	for _, s := range collector.Statuses() {
+		if _, ok := nc.Collectors[s.Name]; ok && s.LastRun != nil && !s.LastRun.Success && !s.LastRun.NoData {
+			failed = append(failed, fmt.Sprintf("%s: %s", s.Name, s.LastRun.Error))
+		}
+	}
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		return fmt.Errorf("collectors failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
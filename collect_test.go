@@ -0,0 +1,71 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux && !noloadavg && !nofilefd
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+)
+
+func TestRunCollect(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, err := kingpin.CommandLine.Parse([]string{"--path.procfs=collector/fixtures/proc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runCollect(&out, "text", "", labelsConfig{}, []string{"loadavg"}, logger); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "node_load1 0.21\n") {
+		t.Errorf("want load average from fixtures, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runCollect(&out, "json", "", labelsConfig{}, []string{"loadavg"}, logger); err != nil {
+		t.Fatal(err)
+	}
+	var families []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &families); err != nil {
+		t.Fatalf("invalid JSON output: %s", err)
+	}
+	if len(families) == 0 || families[0].Name != "node_load1" {
+		t.Errorf("want node_load1 first, got %+v", families)
+	}
+
+	// Collectors keep the paths they were created with, so fail one which
+	// didn't run yet.
+	if _, err := kingpin.CommandLine.Parse([]string{"--path.procfs=/nonexistent"}); err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+	out.Reset()
+	err := runCollect(&out, "text", "", labelsConfig{}, []string{"filefd"}, logger)
+	if err == nil || !strings.Contains(err.Error(), "filefd") {
+		t.Errorf("want filefd failure, got %v", err)
+	}
+	if !strings.Contains(out.String(), `node_scrape_collector_success{collector="filefd"} 0`) {
+		t.Errorf("want metrics printed despite the failure, got:\n%s", out.String())
+	}
+}
@@ -35,6 +35,7 @@ var (
 type arpCollector struct {
 	fs           procfs.FS
 	deviceFilter deviceFilter
+	netlink      bool
 	logger       *slog.Logger
 }
 
@@ -60,6 +61,7 @@ func NewARPCollector(logger *slog.Logger) (Collector, error) {
 	return &arpCollector{
 		fs:           fs,
 		deviceFilter: newDeviceFilter(*arpDeviceExclude, *arpDeviceInclude),
+		netlink:      *arpNetlink,
 		logger:       logger,
 	}, nil
 }
@@ -104,7 +106,7 @@ func getTotalArpEntriesRTNL() (map[string]uint32, error) {
 func (c *arpCollector) Update(ch chan<- prometheus.Metric) error {
 	var enumeratedEntry map[string]uint32
 
-	if *arpNetlink {
+	if c.netlink {
 		var err error
 
 		enumeratedEntry, err = getTotalArpEntriesRTNL()
@@ -0,0 +1,162 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	collectorIntervals = collectorDurationsFlag(kingpin.Flag(
+		"collector.collect-interval",
+		"Run a collector in the background on the given interval and serve its last result to scrapes, as <collector>=<duration> (repeatable).",
+	).PlaceHolder("<collector>=<duration>"))
+
+	scrapeCacheAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "scrape", "collector_cache_age_seconds"),
+		"node_exporter: Age of the metrics served by a collector running in the background.",
+		[]string{"collector"},
+		nil,
+	)
+)
+
+// backgroundCollector updates the wrapped collector on a fixed interval,
+// independently of scrapes, and serves the metrics of the last update. This
+// caps the load an expensive collector puts on the host regardless of the
+// number of scrapers.
+type backgroundCollector struct {
+	name      string
+	collector Collector
+	interval  time.Duration
+	timeout   time.Duration
+	logger    *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// ready is closed once the first update has finished.
+	ready chan struct{}
+
+	mtx     sync.RWMutex
+	metrics []prometheus.Metric
+	err     error
+	updated time.Time
+}
+
+func newBackgroundCollector(name string, c Collector, interval, timeout time.Duration, logger *slog.Logger) *backgroundCollector {
+	ctx, cancel := context.WithCancel(context.Background())
+	bc := &backgroundCollector{
+		name:      name,
+		collector: c,
+		interval:  interval,
+		timeout:   timeout,
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+		ready:     make(chan struct{}),
+	}
+	go bc.run()
+	return bc
+}
+
+func (c *backgroundCollector) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.refresh()
+	close(c.ready)
+	for {
+		select {
+		case <-ticker.C:
+			c.refresh()
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh updates the wrapped collector once and replaces the stored metrics
+// with the result, honouring the collector's configured timeout.
+func (c *backgroundCollector) refresh() {
+	ctx := c.ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var (
+		metrics []prometheus.Metric
+		ch      = make(chan prometheus.Metric)
+		done    = make(chan struct{})
+	)
+	go func() {
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+		close(done)
+	}()
+
+	begin := time.Now()
+	err := workers.acquire(ctx, lastDuration(c.name))
+	if err == nil {
+		err = update(ctx, c.collector, ch, nil)
+		workers.release()
+	}
+	close(ch)
+	<-done
+	c.logger.Debug("background update finished", "name", c.name, "duration_seconds", time.Since(begin).Seconds(), "err", err)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.metrics = metrics
+	c.err = err
+	c.updated = begin
+}
+
+// Update implements Collector.
+func (c *backgroundCollector) Update(ch chan<- prometheus.Metric) error {
+	return c.UpdateContext(context.Background(), ch)
+}
+
+// UpdateContext serves the metrics and the error of the last background
+// update. Before the first update has finished, it waits for it.
+func (c *backgroundCollector) UpdateContext(ctx context.Context, ch chan<- prometheus.Metric) error {
+	select {
+	case <-c.ready:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	for _, m := range c.metrics {
+		ch <- m
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeCacheAgeDesc, prometheus.GaugeValue, time.Since(c.updated).Seconds(), c.name)
+	return c.err
+}
+
+// Close stops the background updates and closes the collector.
+func (c *backgroundCollector) Close() {
+	c.cancel()
+	if closer, ok := c.collector.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type countingCollector struct {
+	updates atomic.Int64
+}
+
+func (c *countingCollector) Update(ch chan<- prometheus.Metric) error {
+	c.updates.Add(1)
+	ch <- prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 1)
+	return nil
+}
+
+func TestBackgroundCollector(t *testing.T) {
+	c := &countingCollector{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	bc := newBackgroundCollector("test", c, time.Hour, 0, logger)
+	defer bc.Close()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(&NodeCollector{Collectors: map[string]Collector{"test": bc}, logger: logger})
+
+	want := `# HELP node_test_value Test value.
+# TYPE node_test_value gauge
+node_test_value 1
+`
+	for range 3 {
+		if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "node_test_value"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := testutil.GatherAndCount(reg, "node_scrape_collector_cache_age_seconds"); err != nil || n != 1 {
+		t.Errorf("expected one cache age series, got %d (err: %v)", n, err)
+	}
+	if want, got := int64(1), c.updates.Load(); want != got {
+		t.Errorf("expected %d background update, got %d", want, got)
+	}
+}
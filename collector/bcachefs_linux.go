@@ -114,6 +114,7 @@ func init() {
 // bcachefsCollector collects metrics from bcachefs filesystems.
 type bcachefsCollector struct {
 	fs     bcachefs.FS
+	paths  Paths
 	logger *slog.Logger
 }
 
@@ -126,6 +127,7 @@ func NewBcachefsCollector(logger *slog.Logger) (Collector, error) {
 
 	return &bcachefsCollector{
 		fs:     fs,
+		paths:  currentPaths(),
 		logger: logger,
 	}, nil
 }
@@ -135,7 +137,7 @@ func (c *bcachefsCollector) Update(ch chan<- prometheus.Metric) error {
 	stats, err := c.fs.Stats()
 	if err != nil {
 		if os.IsNotExist(err) {
-			c.logger.Debug("bcachefs sysfs path does not exist", "path", sysFilePath("fs/bcachefs"))
+			c.logger.Debug("bcachefs sysfs path does not exist", "path", c.paths.sysFilePath("fs/bcachefs"))
 			return ErrNoData
 		}
 		return fmt.Errorf("failed to retrieve bcachefs stats: %w", err)
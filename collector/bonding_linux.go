@@ -28,6 +28,7 @@ import (
 
 type bondingCollector struct {
 	slaves, active typedDesc
+	paths          Paths
 	logger         *slog.Logger
 }
 
@@ -49,13 +50,14 @@ func NewBondingCollector(logger *slog.Logger) (Collector, error) {
 			"Number of active slaves per bonding interface.",
 			[]string{"master"}, nil,
 		), prometheus.GaugeValue},
+		paths:  currentPaths(),
 		logger: logger,
 	}, nil
 }
 
 // Update reads and exposes bonding states, implements Collector interface. Caution: This works only on linux.
 func (c *bondingCollector) Update(ch chan<- prometheus.Metric) error {
-	statusfile := sysFilePath("class/net")
+	statusfile := c.paths.sysFilePath("class/net")
 	bondingStats, err := readBondingStats(statusfile)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -30,6 +30,7 @@ import (
 // A btrfsCollector is a Collector which gathers metrics from Btrfs filesystems.
 type btrfsCollector struct {
 	fs     btrfs.FS
+	paths  Paths
 	logger *slog.Logger
 }
 
@@ -46,6 +47,7 @@ func NewBtrfsCollector(logger *slog.Logger) (Collector, error) {
 
 	return &btrfsCollector{
 		fs:     fs,
+		paths:  currentPaths(),
 		logger: logger,
 	}, nil
 }
@@ -101,7 +103,7 @@ type btrfsIoctlFsStats struct {
 func (c *btrfsCollector) getIoctlStats() (map[string]*btrfsIoctlFsStats, error) {
 	// Instead of introducing more ioctl calls to scan for all btrfs
 	// filesystems re-use our mount point utils to find known mounts
-	mountsList, err := mountPointDetails(c.logger)
+	mountsList, err := mountPointDetails(c.paths, c.logger)
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +123,7 @@ func (c *btrfsCollector) getIoctlStats() (map[string]*btrfsIoctlFsStats, error)
 			continue
 		}
 
-		mountPath := rootfsFilePath(mount.mountPoint)
+		mountPath := c.paths.rootfsFilePath(mount.mountPoint)
 
 		fs, err := dennwc.Open(mountPath, true)
 		if err != nil {
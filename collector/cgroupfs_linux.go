@@ -0,0 +1,84 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// cgroupv2Root returns the mount point of the unified cgroup hierarchy,
+// either mounted on its own or, in hybrid setups, next to the v1
+// controllers.
+func cgroupv2Root(p Paths) (string, error) {
+	for _, root := range []string{"fs/cgroup", "fs/cgroup/unified"} {
+		root = p.sysFilePath(root)
+		if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+			return root, nil
+		}
+	}
+	return "", ErrNoData
+}
+
+// walkCgroups calls fn for the directory of every cgroup under root down to
+// depth, root being at depth 0 and a negative depth walking all of them.
+// Cgroups are named by their path from root, e.g. /system.slice/ssh.service,
+// which filter is applied to.
+func walkCgroups(root string, depth int, filter deviceFilter, fn func(dir, cgroup string) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// The cgroup was removed while walking.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		cgroup := "/"
+		if rel != "." {
+			cgroup += filepath.ToSlash(rel)
+			if depth >= 0 && strings.Count(cgroup, "/") > depth {
+				return fs.SkipDir
+			}
+		}
+		if filter.ignored(cgroup) {
+			return nil
+		}
+		if err := fn(path, cgroup); err != nil {
+			return fmt.Errorf("cgroup %s: %w", cgroup, err)
+		}
+		return nil
+	})
+}
+
+// cgroupFileErr returns err unless it tells that the file doesn't exist, its
+// controller not being enabled or the cgroup being gone, or that it is
+// disabled, as pressure files are by cgroup.pressure.
+func cgroupFileErr(err error) error {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENODEV) || errors.Is(err, syscall.ENOTSUP) {
+		return nil
+	}
+	return err
+}
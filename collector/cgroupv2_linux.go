@@ -0,0 +1,291 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocgroupv2
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"github.com/prometheus/procfs/blockdevice"
+)
+
+const cgroupv2CollectorSubsystem = "cgroup"
+
+var (
+	cgroupv2Depth   = kingpin.Flag("collector.cgroupv2.depth", "Depth of the cgroup hierarchy to walk, the root cgroup being at depth 0.").Default("2").Int()
+	cgroupv2Include = kingpin.Flag("collector.cgroupv2.cgroup-include", "Regexp of cgroup paths to include, e.g. /system.slice/.+\\.service.").String()
+	cgroupv2Exclude = kingpin.Flag("collector.cgroupv2.cgroup-exclude", "Regexp of cgroup paths to exclude.").String()
+
+	cgroupv2Labels = []string{"cgroup"}
+
+	// cgroupv2CPUStats maps the cpu.stat keys exposed to their metric and the
+	// divisor turning microseconds into seconds.
+	cgroupv2CPUStats = map[string]struct {
+		desc    *prometheus.Desc
+		divisor float64
+	}{
+		"usage_usec":     {cgroupv2Desc("cpu_usage_seconds_total", "Total CPU time consumed by the tasks of the cgroup."), 1e6},
+		"user_usec":      {cgroupv2Desc("cpu_user_seconds_total", "CPU time consumed by the tasks of the cgroup in user mode."), 1e6},
+		"system_usec":    {cgroupv2Desc("cpu_system_seconds_total", "CPU time consumed by the tasks of the cgroup in kernel mode."), 1e6},
+		"nr_periods":     {cgroupv2Desc("cpu_periods_total", "Number of enforcement periods elapsed for the CPU bandwidth limit of the cgroup."), 1},
+		"nr_throttled":   {cgroupv2Desc("cpu_throttled_periods_total", "Number of enforcement periods in which the cgroup was throttled."), 1},
+		"throttled_usec": {cgroupv2Desc("cpu_throttled_seconds_total", "Total time the tasks of the cgroup were throttled."), 1e6},
+	}
+
+	// cgroupv2IOStats maps the io.stat keys to their metric.
+	cgroupv2IOStats = map[string]*prometheus.Desc{
+		"rbytes": cgroupv2Desc("io_read_bytes_total", "Number of bytes read by the cgroup from the device.", "device"),
+		"wbytes": cgroupv2Desc("io_written_bytes_total", "Number of bytes written by the cgroup to the device.", "device"),
+		"rios":   cgroupv2Desc("io_reads_total", "Number of read operations of the cgroup on the device.", "device"),
+		"wios":   cgroupv2Desc("io_writes_total", "Number of write operations of the cgroup on the device.", "device"),
+		"dbytes": cgroupv2Desc("io_discarded_bytes_total", "Number of bytes discarded by the cgroup on the device.", "device"),
+		"dios":   cgroupv2Desc("io_discards_total", "Number of discard operations of the cgroup on the device.", "device"),
+	}
+
+	cgroupv2MemoryUsage  = cgroupv2Desc("memory_usage_bytes", "Memory currently used by the cgroup and its descendants.")
+	cgroupv2MemoryMax    = cgroupv2Desc("memory_max_bytes", "Memory usage hard limit of the cgroup, absent if unlimited.")
+	cgroupv2MemoryEvents = cgroupv2Desc("memory_events_total", "Number of memory events of the cgroup and its descendants, by event.", "event")
+	cgroupv2Pids         = cgroupv2Desc("pids", "Number of processes in the cgroup and its descendants.")
+	cgroupv2PidsMax      = cgroupv2Desc("pids_max", "Limit of processes in the cgroup, absent if unlimited.")
+
+	// cgroupv2Pressure holds the metrics of the pressure files, for the
+	// some and the full lines.
+	cgroupv2Pressure = map[string][2]*prometheus.Desc{
+		psiResourceCPU: {
+			cgroupv2Desc("pressure_cpu_waiting_seconds_total", "Total time in seconds that processes of the cgroup have waited for CPU time."),
+			cgroupv2Desc("pressure_cpu_stalled_seconds_total", "Total time in seconds no process of the cgroup could make progress due to CPU congestion."),
+		},
+		psiResourceIO: {
+			cgroupv2Desc("pressure_io_waiting_seconds_total", "Total time in seconds that processes of the cgroup have waited due to IO congestion."),
+			cgroupv2Desc("pressure_io_stalled_seconds_total", "Total time in seconds no process of the cgroup could make progress due to IO congestion."),
+		},
+		psiResourceMemory: {
+			cgroupv2Desc("pressure_memory_waiting_seconds_total", "Total time in seconds that processes of the cgroup have waited for memory."),
+			cgroupv2Desc("pressure_memory_stalled_seconds_total", "Total time in seconds no process of the cgroup could make progress due to memory congestion."),
+		},
+	}
+)
+
+func cgroupv2Desc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cgroupv2CollectorSubsystem, name),
+		help, append(cgroupv2Labels, labels...), nil,
+	)
+}
+
+type cgroupv2Collector struct {
+	paths  Paths
+	depth  int
+	filter deviceFilter
+	logger *slog.Logger
+}
+
+func init() {
+	registerCollector("cgroupv2", defaultDisabled, NewCgroupv2Collector, "cgroup-include", "cgroup-exclude")
+}
+
+// NewCgroupv2Collector returns a new Collector exposing the resource usage of
+// the cgroups of the unified (v2) hierarchy.
+func NewCgroupv2Collector(logger *slog.Logger) (Collector, error) {
+	return &cgroupv2Collector{
+		paths:  currentPaths(),
+		depth:  *cgroupv2Depth,
+		filter: newDeviceFilter(*cgroupv2Exclude, *cgroupv2Include),
+		logger: logger,
+	}, nil
+}
+
+func (c *cgroupv2Collector) Update(ch chan<- prometheus.Metric) error {
+	root, err := cgroupv2Root(c.paths)
+	if err != nil {
+		c.logger.Debug("cgroup v2 hierarchy not found", "path", c.paths.sysFilePath("fs/cgroup"))
+		return err
+	}
+	devices := c.deviceNames()
+
+	return walkCgroups(root, c.depth, c.filter, func(dir, cgroup string) error {
+		return c.updateCgroup(ch, dir, cgroup, devices)
+	})
+}
+
+// deviceNames maps the <major>:<minor> numbers of the block devices to their
+// names.
+func (c *cgroupv2Collector) deviceNames() map[string]string {
+	names := map[string]string{}
+	blockFS, err := blockdevice.NewFS(c.paths.procMountPoint(), c.paths.sysMountPoint())
+	if err != nil {
+		c.logger.Debug("couldn't open block devices", "err", err)
+		return names
+	}
+	stats, err := blockFS.ProcDiskstats()
+	if err != nil {
+		c.logger.Debug("couldn't read block devices", "err", err)
+		return names
+	}
+	for _, s := range stats {
+		names[fmt.Sprintf("%d:%d", s.MajorNumber, s.MinorNumber)] = s.DeviceName
+	}
+	return names
+}
+
+// updateCgroup exposes the stats of the cgroup in dir. Files missing as their
+// controller isn't enabled for the cgroup, or as the cgroup is gone, are
+// skipped.
+func (c *cgroupv2Collector) updateCgroup(ch chan<- prometheus.Metric, dir, cgroup string, devices map[string]string) error {
+	cpuStats, err := readCgroupKeyedFile(filepath.Join(dir, "cpu.stat"))
+	if cgroupFileErr(err) != nil {
+		return err
+	}
+	for key, value := range cpuStats {
+		if stat, ok := cgroupv2CPUStats[key]; ok {
+			ch <- prometheus.MustNewConstMetric(stat.desc, prometheus.CounterValue, float64(value)/stat.divisor, cgroup)
+		}
+	}
+
+	usage, err := readUintFromFile(filepath.Join(dir, "memory.current"))
+	switch {
+	case err == nil:
+		ch <- prometheus.MustNewConstMetric(cgroupv2MemoryUsage, prometheus.GaugeValue, float64(usage), cgroup)
+	case cgroupFileErr(err) != nil:
+		return err
+	}
+	if err := c.updateLimit(ch, filepath.Join(dir, "memory.max"), cgroupv2MemoryMax, cgroup); err != nil {
+		return err
+	}
+	events, err := readCgroupKeyedFile(filepath.Join(dir, "memory.events"))
+	if cgroupFileErr(err) != nil {
+		return err
+	}
+	for event, value := range events {
+		ch <- prometheus.MustNewConstMetric(cgroupv2MemoryEvents, prometheus.CounterValue, float64(value), cgroup, event)
+	}
+
+	if err := c.updateIO(ch, filepath.Join(dir, "io.stat"), cgroup, devices); err != nil {
+		return err
+	}
+
+	pids, err := readUintFromFile(filepath.Join(dir, "pids.current"))
+	switch {
+	case err == nil:
+		ch <- prometheus.MustNewConstMetric(cgroupv2Pids, prometheus.GaugeValue, float64(pids), cgroup)
+	case cgroupFileErr(err) != nil:
+		return err
+	}
+	if err := c.updateLimit(ch, filepath.Join(dir, "pids.max"), cgroupv2PidsMax, cgroup); err != nil {
+		return err
+	}
+
+	for res, descs := range cgroupv2Pressure {
+		stats, err := readPSIStats(filepath.Join(dir, res+".pressure"))
+		if err != nil {
+			if cgroupFileErr(err) != nil {
+				return err
+			}
+			continue
+		}
+		for i, line := range []*procfs.PSILine{stats.Some, stats.Full} {
+			if line != nil {
+				ch <- prometheus.MustNewConstMetric(descs[i], prometheus.CounterValue, float64(line.Total)/1000.0/1000.0, cgroup)
+			}
+		}
+	}
+	return nil
+}
+
+// updateLimit exposes the limit in path, unless it is "max".
+func (c *cgroupv2Collector) updateLimit(ch chan<- prometheus.Metric, path string, desc *prometheus.Desc, cgroup string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cgroupFileErr(err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return nil
+	}
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value in %s: %w", path, err)
+	}
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(limit), cgroup)
+	return nil
+}
+
+// updateIO exposes the io.stat lines, of the form
+// <major>:<minor> rbytes=<n> wbytes=<n> rios=<n> wios=<n> dbytes=<n> dios=<n>.
+func (c *cgroupv2Collector) updateIO(ch chan<- prometheus.Metric, path, cgroup string, devices map[string]string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return cgroupFileErr(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		device, ok := devices[fields[0]]
+		if !ok {
+			device = fields[0]
+		}
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			desc, known := cgroupv2IOStats[key]
+			if !ok || !known {
+				continue
+			}
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value in %s: %w", path, err)
+			}
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(v), cgroup, device)
+		}
+	}
+	return scanner.Err()
+}
+
+// readCgroupKeyedFile reads a file of <key> <value> lines, like cpu.stat.
+func readCgroupKeyedFile(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in %s: %w", path, err)
+		}
+		values[key] = v
+	}
+	return values, scanner.Err()
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocgroupv2
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type testCgroupv2Collector struct {
+	c Collector
+}
+
+func (c testCgroupv2Collector) Collect(ch chan<- prometheus.Metric) {
+	if err := c.c.Update(ch); err != nil {
+		panic(err)
+	}
+}
+
+func (c testCgroupv2Collector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func TestCgroupv2Filter(t *testing.T) {
+	c := &cgroupv2Collector{
+		paths:  Paths{Procfs: "fixtures/proc", Sysfs: "fixtures/sys"},
+		depth:  3,
+		filter: newDeviceFilter("", `/(system|user)\.slice/.+`),
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	want := `# HELP node_cgroup_io_read_bytes_total Number of bytes read by the cgroup from the device.
+# TYPE node_cgroup_io_read_bytes_total counter
+node_cgroup_io_read_bytes_total{cgroup="/system.slice/ssh.service",device="253:7"} 4096
+node_cgroup_io_read_bytes_total{cgroup="/system.slice/ssh.service",device="sda"} 8.761344e+06
+# HELP node_cgroup_memory_max_bytes Memory usage hard limit of the cgroup, absent if unlimited.
+# TYPE node_cgroup_memory_max_bytes gauge
+node_cgroup_memory_max_bytes{cgroup="/system.slice/ssh.service"} 5.36870912e+08
+# HELP node_cgroup_pids Number of processes in the cgroup and its descendants.
+# TYPE node_cgroup_pids gauge
+node_cgroup_pids{cgroup="/system.slice/ssh.service"} 3
+node_cgroup_pids{cgroup="/user.slice/user-1000.slice"} 238
+node_cgroup_pids{cgroup="/user.slice/user-1000.slice/session-1.scope"} 12
+# HELP node_cgroup_pids_max Limit of processes in the cgroup, absent if unlimited.
+# TYPE node_cgroup_pids_max gauge
+node_cgroup_pids_max{cgroup="/system.slice/ssh.service"} 100
+`
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(testCgroupv2Collector{c})
+	err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"node_cgroup_io_read_bytes_total", "node_cgroup_memory_max_bytes", "node_cgroup_pids", "node_cgroup_pids_max")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCgroupv2NoHierarchy(t *testing.T) {
+	c := &cgroupv2Collector{
+		paths:  Paths{Procfs: "fixtures/proc", Sysfs: t.TempDir()},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := c.Update(make(chan prometheus.Metric)); err != ErrNoData {
+		t.Errorf("want ErrNoData, got %v", err)
+	}
+}
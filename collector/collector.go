@@ -15,14 +15,21 @@
 package collector
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
 // Namespace defines the common namespace to be used by all metrics.
@@ -41,6 +48,43 @@ var (
 		[]string{"collector"},
 		nil,
 	)
+	scrapeTimeoutDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "scrape", "collector_timeout"),
+		"node_exporter: Whether a collector was abandoned because it exceeded its timeout.",
+		[]string{"collector"},
+		nil,
+	)
+	scrapeSeriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "scrape", "collector_series"),
+		"node_exporter: Number of metrics a collector exposed, histograms and summaries counting once.",
+		[]string{"collector"},
+		nil,
+	)
+	scrapeTruncatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "scrape", "collector_truncated"),
+		"node_exporter: Whether metrics of a collector were dropped because it exceeded its series limit.",
+		[]string{"collector"},
+		nil,
+	)
+)
+
+var (
+	collectorTimeout = kingpin.Flag(
+		"collector.scrape-timeout",
+		"Maximum duration of a single collector update. Collectors exceeding it are abandoned for the scrape. 0 disables the timeout.",
+	).Default("0s").Duration()
+	collectorTimeoutOverrides = collectorDurationsFlag(kingpin.Flag(
+		"collector.scrape-timeout.override",
+		"Per-collector timeout overriding --collector.scrape-timeout, as <collector>=<duration> (repeatable).",
+	).PlaceHolder("<collector>=<duration>"))
+	collectorSeriesLimit = kingpin.Flag(
+		"collector.series-limit",
+		"Maximum number of metrics a single collector may expose per scrape, histograms and summaries counting once. Metrics beyond it are dropped. 0 disables the limit.",
+	).Default("0").Int()
+	collectorSeriesLimitOverrides = collectorCountsFlag(kingpin.Flag(
+		"collector.series-limit.override",
+		"Per-collector series limit overriding --collector.series-limit, as <collector>=<count> (repeatable).",
+	).PlaceHolder("<collector>=<count>"))
 )
 
 const (
@@ -54,9 +98,16 @@ var (
 	initiatedCollectors    = make(map[string]Collector)
 	collectorState         = make(map[string]*bool)
 	forcedCollectors       = map[string]bool{} // collectors which have been explicitly enabled or disabled
+	// requestOptions are the options collectors accept per request, see
+	// NewNodeCollectorWithOptions.
+	requestOptions = make(map[string][]string)
 )
 
-func registerCollector(collector string, isDefaultEnabled bool, factory func(logger *slog.Logger) (Collector, error)) {
+// registerCollector registers the factory of a collector. The options, named
+// like its flags without the "collector.<name>." prefix, may be set per
+// request. They must only affect the collector's construction, as it is
+// created anew for the requests setting them.
+func registerCollector(collector string, isDefaultEnabled bool, factory func(logger *slog.Logger) (Collector, error), options ...string) {
 	var helpDefaultState string
 	if isDefaultEnabled {
 		helpDefaultState = "enabled"
@@ -72,12 +123,37 @@ func registerCollector(collector string, isDefaultEnabled bool, factory func(log
 	collectorState[collector] = flag
 
 	factories[collector] = factory
+	requestOptions[collector] = options
 }
 
 // NodeCollector implements the prometheus.Collector interface.
 type NodeCollector struct {
 	Collectors map[string]Collector
 	logger     *slog.Logger
+	ctx        context.Context
+	// timeouts holds the update timeouts of the collectors, captured at
+	// creation. Collectors missing from it use the global settings.
+	timeouts map[string]time.Duration
+	// limits holds the series limits of the collectors, captured at
+	// creation like timeouts.
+	limits map[string]int
+	// relabelConfigs holds the relabeling rules of the collectors and
+	// nodeRelabelConfigs the ones applied to all metrics, captured at
+	// creation like timeouts.
+	relabelConfigs     map[string][]*RelabelConfig
+	nodeRelabelConfigs []*RelabelConfig
+	// owned are the collectors created for this NodeCollector only, which
+	// are stopped by Close.
+	owned []Collector
+}
+
+// WithContext returns a copy of the NodeCollector whose collection is bound
+// to ctx. Collectors still running when ctx is done are abandoned and their
+// metrics are dropped from the scrape.
+func (n *NodeCollector) WithContext(ctx context.Context) *NodeCollector {
+	nc := *n
+	nc.ctx = ctx
+	return &nc
 }
 
 // DisableDefaultCollectors sets the collector state to false for all collectors which
@@ -102,8 +178,26 @@ func collectorFlagAction(collector string) func(ctx *kingpin.ParseContext) error
 	}
 }
 
+// Options are per-request collector options, keyed by collector name and
+// option name. Only the options declared when registering a collector are
+// accepted.
+type Options map[string]map[string][]string
+
 // NewNodeCollector creates a new NodeCollector.
 func NewNodeCollector(logger *slog.Logger, filters ...string) (*NodeCollector, error) {
+	return NewNodeCollectorWithOptions(logger, nil, filters...)
+}
+
+// NewNodeCollectorWithOptions creates a new NodeCollector like
+// NewNodeCollector, with the given options applied on top of the global
+// settings. Collectors with options are created for the returned
+// NodeCollector only and stopped by its Close, the others are shared.
+func NewNodeCollectorWithOptions(logger *slog.Logger, options Options, filters ...string) (_ *NodeCollector, err error) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	initiatedCollectorsMtx.Lock()
+	defer initiatedCollectorsMtx.Unlock()
+
 	f := make(map[string]bool)
 	for _, filter := range filters {
 		enabled, exist := collectorState[filter]
@@ -115,65 +209,556 @@ func NewNodeCollector(logger *slog.Logger, filters ...string) (*NodeCollector, e
 		}
 		f[filter] = true
 	}
-	collectors := make(map[string]Collector)
-	initiatedCollectorsMtx.Lock()
-	defer initiatedCollectorsMtx.Unlock()
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		enabled, exist := collectorState[name]
+		if !exist {
+			return nil, fmt.Errorf("options for missing collector: %s", name)
+		}
+		if !*enabled || (len(f) > 0 && !f[name]) {
+			return nil, fmt.Errorf("options for disabled collector: %s", name)
+		}
+		for _, option := range slices.Sorted(maps.Keys(options[name])) {
+			if !slices.Contains(requestOptions[name], option) {
+				return nil, fmt.Errorf("collector %s: option %q can't be set per request", name, option)
+			}
+		}
+	}
+
+	nc := &NodeCollector{
+		Collectors:         make(map[string]Collector),
+		timeouts:           make(map[string]time.Duration),
+		limits:             make(map[string]int),
+		relabelConfigs:     make(map[string][]*RelabelConfig),
+		nodeRelabelConfigs: nodeRelabelConfigs,
+		logger:             logger,
+	}
+	defer func() {
+		if err != nil {
+			nc.Close()
+		}
+	}()
 	for key, enabled := range collectorState {
 		if !*enabled || (len(f) > 0 && !f[key]) {
 			continue
 		}
-		if collector, ok := initiatedCollectors[key]; ok {
-			collectors[key] = collector
+		nc.timeouts[key] = timeoutFor(key)
+		nc.limits[key] = seriesLimitFor(key)
+		nc.relabelConfigs[key] = collectorRelabelConfigs[key]
+		if opts, ok := options[key]; ok {
+			collector, err := newRequestCollector(key, opts, logger.With("collector", key))
+			if err != nil {
+				return nil, err
+			}
+			nc.Collectors[key] = collector
+			nc.owned = append(nc.owned, collector)
+		} else if collector, ok := initiatedCollectors[key]; ok {
+			nc.Collectors[key] = collector
 		} else {
-			collector, err := factories[key](logger.With("collector", key))
+			collector, err := newCollector(key, logger.With("collector", key))
 			if err != nil {
 				return nil, err
 			}
-			collectors[key] = collector
+			nc.Collectors[key] = collector
 			initiatedCollectors[key] = collector
 		}
 	}
-	return &NodeCollector{Collectors: collectors, logger: logger}, nil
+	return nc, nil
+}
+
+// newRequestCollector creates the named collector with options set on top of
+// the global settings. It is never run in the background, as it only serves
+// a single request. Callers must hold configMtx.
+func newRequestCollector(name string, options map[string][]string, logger *slog.Logger) (Collector, error) {
+	global := currentSettings()
+	defer restoreSettings(global)
+	delete(*collectorIntervals, name)
+	for _, option := range slices.Sorted(maps.Keys(options)) {
+		flag := kingpin.CommandLine.GetFlag(fmt.Sprintf("collector.%s.%s", name, option))
+		if flag == nil {
+			return nil, fmt.Errorf("collector %s: unknown option %q", name, option)
+		}
+		if err := writeFlag(flag.Model().Value, options[option]); err != nil {
+			return nil, fmt.Errorf("collector %s: option %q: %w", name, option, err)
+		}
+	}
+	return newCollector(name, slog.New(requestLogHandler{logger.Handler()}))
+}
+
+// requestLogHandler logs the informational messages of the collectors created
+// for a single request at debug level, as their setup, e.g. the flags they
+// parsed, is logged again on every such request.
+type requestLogHandler struct {
+	slog.Handler
+}
+
+func (h requestLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level == slog.LevelInfo {
+		level = slog.LevelDebug
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+func (h requestLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelInfo {
+		r.Level = slog.LevelDebug
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h requestLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestLogHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestLogHandler) WithGroup(name string) slog.Handler {
+	return requestLogHandler{h.Handler.WithGroup(name)}
+}
+
+// IsRequestOption returns whether the option of the named collector may be
+// set per request.
+func IsRequestOption(name, option string) bool {
+	return slices.Contains(requestOptions[name], option)
+}
+
+// NewProfileCollector creates a NodeCollector for the collectors named in
+// configs, regardless of whether they are enabled. Each collector is set up
+// with the settings of its Config applied on top of the global ones, except
+// for Enabled, which excludes the collector if false. Collectors without
+// settings of their own are shared with NewNodeCollector, the others are
+// created for the returned NodeCollector only and stopped by its Close.
+func NewProfileCollector(logger *slog.Logger, configs map[string]Config) (*NodeCollector, error) {
+	return newIsolatedCollector(logger, nil, configs)
+}
+
+// NewTargetCollector creates a NodeCollector whose collectors read from paths
+// instead of the locations given by the --path.* flags. Without configs, it
+// serves the enabled collectors, otherwise the ones named in configs as
+// NewProfileCollector does. All collectors are created for the returned
+// NodeCollector only and stopped by its Close.
+func NewTargetCollector(logger *slog.Logger, paths Paths, configs map[string]Config) (*NodeCollector, error) {
+	return newIsolatedCollector(logger, &paths, configs)
+}
+
+func newIsolatedCollector(logger *slog.Logger, paths *Paths, configs map[string]Config) (_ *NodeCollector, err error) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	initiatedCollectorsMtx.Lock()
+	defer initiatedCollectorsMtx.Unlock()
+
+	global := currentSettings()
+	defer restoreSettings(global)
+	if paths != nil {
+		defer paths.set()()
+	}
+
+	nc := &NodeCollector{
+		Collectors:         make(map[string]Collector),
+		timeouts:           make(map[string]time.Duration),
+		limits:             make(map[string]int),
+		relabelConfigs:     make(map[string][]*RelabelConfig),
+		nodeRelabelConfigs: nodeRelabelConfigs,
+		logger:             logger,
+	}
+	defer func() {
+		if err != nil {
+			nc.Close()
+		}
+	}()
+
+	var names []string
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		cfg := configs[name]
+		if cfg.Enabled != nil && !*cfg.Enabled {
+			continue
+		}
+		if err := applyCollectorConfig(name, cfg); err != nil {
+			return nil, fmt.Errorf("collector %s: %w", name, err)
+		}
+		names = append(names, name)
+	}
+	if len(configs) == 0 {
+		for _, name := range slices.Sorted(maps.Keys(collectorState)) {
+			if *collectorState[name] {
+				names = append(names, name)
+			}
+		}
+	}
+
+	current := currentSettings()
+	for _, name := range names {
+		nc.timeouts[name] = timeoutFor(name)
+		nc.limits[name] = seriesLimitFor(name)
+		nc.relabelConfigs[name] = collectorRelabelConfigs[name]
+		shared := paths == nil && current.signature(name) == global.signature(name)
+		if c, ok := initiatedCollectors[name]; ok && shared {
+			nc.Collectors[name] = c
+			continue
+		}
+		c, err := newCollector(name, logger.With("collector", name))
+		if err != nil {
+			return nil, err
+		}
+		nc.Collectors[name] = c
+		if shared {
+			initiatedCollectors[name] = c
+		} else {
+			nc.owned = append(nc.owned, c)
+		}
+	}
+	return nc, nil
+}
+
+// Close stops the collectors created for this NodeCollector only, see
+// NewProfileCollector and NewTargetCollector.
+func (n *NodeCollector) Close() {
+	for _, c := range n.owned {
+		if closer, ok := c.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
+}
+
+// newCollector creates the named collector, running it in the background if
+// a collection interval is configured for it. A panic of its factory, e.g. on
+// an invalid regular expression passed via configuration, is returned as an
+// error.
+func newCollector(name string, logger *slog.Logger) (c Collector, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("couldn't create %s collector: %v", name, r)
+		}
+	}()
+	c, err = factories[name](logger)
+	if err != nil {
+		return nil, err
+	}
+	if interval := (*collectorIntervals)[name]; interval > 0 {
+		c = newBackgroundCollector(name, c, interval, timeoutFor(name), logger)
+	}
+	return c, nil
 }
 
 // Describe implements the prometheus.Collector interface.
 func (n NodeCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- scrapeDurationDesc
 	ch <- scrapeSuccessDesc
+	ch <- scrapeTimeoutDesc
+	ch <- scrapeSeriesDesc
+	ch <- scrapeTruncatedDesc
+	ch <- scrapeCacheAgeDesc
 }
 
 // Collect implements the prometheus.Collector interface.
 func (n NodeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := n.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(n.nodeRelabelConfigs) > 0 {
+		relabeled := make(chan prometheus.Metric)
+		done := make(chan struct{})
+		go func(out chan<- prometheus.Metric) {
+			for m := range relabeled {
+				if m, ok := relabelMetric(m, n.nodeRelabelConfigs); ok {
+					out <- m
+				}
+			}
+			close(done)
+		}(ch)
+		defer func() {
+			close(relabeled)
+			<-done
+		}()
+		ch = relabeled
+	}
 	wg := sync.WaitGroup{}
 	wg.Add(len(n.Collectors))
 	for name, c := range n.Collectors {
 		go func(name string, c Collector) {
-			execute(name, c, ch, n.logger)
+			execute(ctx, name, c, n.timeout(name), n.seriesLimit(name), n.relabelConfigs[name], ch, n.logger)
 			wg.Done()
 		}(name, c)
 	}
 	wg.Wait()
 }
 
-func execute(name string, c Collector, ch chan<- prometheus.Metric, logger *slog.Logger) {
-	begin := time.Now()
-	err := c.Update(ch)
-	duration := time.Since(begin)
+// timeout returns the update timeout of the named collector.
+func (n NodeCollector) timeout(name string) time.Duration {
+	if timeout, ok := n.timeouts[name]; ok {
+		return timeout
+	}
+	return timeoutFor(name)
+}
+
+// seriesLimit returns the series limit of the named collector.
+func (n NodeCollector) seriesLimit(name string) int {
+	if limit, ok := n.limits[name]; ok {
+		return limit
+	}
+	return seriesLimitFor(name)
+}
+
+// execute updates the collector, sharing the update with concurrent scrapes
+// of the same collector, see joinRun, and sends its metrics along with the
+// scrape metrics to ch.
+func execute(ctx context.Context, name string, c Collector, timeout time.Duration, limit int, relabelConfigs []*RelabelConfig, ch chan<- prometheus.Metric, logger *slog.Logger) {
+	var (
+		begin    = time.Now()
+		duration time.Duration
+		metrics  []prometheus.Metric
+		timedOut float64
+	)
+	r, err := joinRun(ctx, name, c, timeout)
+	if err != nil {
+		// The scrape gave up waiting.
+		duration = time.Since(begin)
+		if errors.Is(err, context.DeadlineExceeded) {
+			timedOut = 1
+		}
+	} else {
+		begin, duration, metrics, err = r.begin, r.duration, r.metrics, r.err
+		if r.timedOut {
+			timedOut = 1
+		}
+	}
+
+	// Relabel the metrics on their way out and drop the ones beyond the
+	// limit. Collectors often send their metrics in map order, so they are
+	// sorted first for the same series to be kept on every scrape.
+	var kept []prometheus.Metric
+	for _, m := range metrics {
+		if m, ok := relabelMetric(m, relabelConfigs); ok {
+			kept = append(kept, m)
+		}
+	}
+	var dropped int
+	if limit > 0 && len(kept) > limit {
+		sortSeries(kept)
+		dropped = len(kept) - limit
+		kept = kept[:limit]
+	}
+	series := len(kept)
+	for _, m := range kept {
+		ch <- m
+	}
+
+	var truncated float64
+	if dropped > 0 {
+		logger.Warn("collector exceeded its series limit", "name", name, "limit", limit, "dropped", dropped)
+		truncated = 1
+	}
 	var success float64
+	run := Run{Time: begin, DurationSeconds: duration.Seconds(), Series: series, Truncated: dropped > 0}
+	cancelled := false
 
 	if err != nil {
-		if IsNoDataError(err) {
+		if timedOut == 1 {
+			logger.Warn("collector timed out", "name", name, "duration_seconds", duration.Seconds())
+			run.TimedOut = true
+		} else if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			logger.Debug("collector cancelled", "name", name, "duration_seconds", duration.Seconds())
+			cancelled = true
+		} else if IsNoDataError(err) {
 			logger.Debug("collector returned no data", "name", name, "duration_seconds", duration.Seconds(), "err", err)
+			run.NoData = true
 		} else {
 			logger.Error("collector failed", "name", name, "duration_seconds", duration.Seconds(), "err", err)
 		}
 		success = 0
+		run.Error = err.Error()
 	} else {
 		logger.Debug("collector succeeded", "name", name, "duration_seconds", duration.Seconds())
 		success = 1
+		run.Success = true
+	}
+	// Cancelled scrapes say nothing about the collector.
+	if !cancelled {
+		recordRun(name, c, run)
 	}
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, duration.Seconds(), name)
 	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success, name)
+	ch <- prometheus.MustNewConstMetric(scrapeTimeoutDesc, prometheus.GaugeValue, timedOut, name)
+	ch <- prometheus.MustNewConstMetric(scrapeSeriesDesc, prometheus.GaugeValue, float64(series), name)
+	ch <- prometheus.MustNewConstMetric(scrapeTruncatedDesc, prometheus.GaugeValue, truncated, name)
+}
+
+// sortSeries sorts metrics by their name and labels.
+func sortSeries(metrics []prometheus.Metric) {
+	type keyedMetric struct {
+		key    string
+		metric prometheus.Metric
+	}
+	keyed := make([]keyedMetric, len(metrics))
+	for i, m := range metrics {
+		keyed[i] = keyedMetric{key: seriesKey(m), metric: m}
+	}
+	slices.SortStableFunc(keyed, func(a, b keyedMetric) int {
+		return strings.Compare(a.key, b.key)
+	})
+	for i, k := range keyed {
+		metrics[i] = k.metric
+	}
+}
+
+// seriesKey returns the name of a metric followed by its labels, which the
+// metric writes sorted.
+func seriesKey(m prometheus.Metric) string {
+	name, _, _ := parseDesc(m.Desc())
+	var pb dto.Metric
+	if m.Write(&pb) != nil {
+		return name
+	}
+	var b strings.Builder
+	b.WriteString(name)
+	for _, lp := range pb.Label {
+		b.WriteByte(0xff)
+		b.WriteString(lp.GetName())
+		b.WriteByte(0xff)
+		b.WriteString(lp.GetValue())
+	}
+	return b.String()
+}
+
+// updateContext calls UpdateContext on collectors implementing
+// ContextCollector and falls back to Update for all others.
+func updateContext(ctx context.Context, c Collector, ch chan<- prometheus.Metric) error {
+	if cc, ok := c.(ContextCollector); ok {
+		return cc.UpdateContext(ctx, ch)
+	}
+	return c.Update(ch)
+}
+
+// update runs the collector in a separate goroutine and buffers its metrics
+// until it returns. If ctx is done first, the collector is abandoned: its
+// metrics are discarded, the remaining ones are drained in the background and
+// the context error is returned. Collectors implementing ContextCollector see
+// the same ctx and are expected to return promptly. finished, if not nil, is
+// called once the collector returned, which is after update returned if the
+// collector was abandoned.
+func update(ctx context.Context, c Collector, ch chan<- prometheus.Metric, finished func()) error {
+	var (
+		metrics = make(chan prometheus.Metric)
+		errCh   = make(chan error, 1)
+		buffer  []prometheus.Metric
+	)
+	go func() {
+		errCh <- updateContext(ctx, c, metrics)
+		close(metrics)
+		if finished != nil {
+			finished()
+		}
+	}()
+	for {
+		select {
+		case m, ok := <-metrics:
+			if !ok {
+				for _, m := range buffer {
+					ch <- m
+				}
+				return <-errCh
+			}
+			buffer = append(buffer, m)
+		case <-ctx.Done():
+			go func() {
+				for range metrics {
+				}
+			}()
+			return ctx.Err()
+		}
+	}
+}
+
+// timeoutFor returns the update timeout configured for the named collector.
+func timeoutFor(name string) time.Duration {
+	if timeout, ok := (*collectorTimeoutOverrides)[name]; ok {
+		return timeout
+	}
+	return *collectorTimeout
+}
+
+// seriesLimitFor returns the series limit configured for the named collector.
+func seriesLimitFor(name string) int {
+	if limit, ok := (*collectorSeriesLimitOverrides)[name]; ok {
+		return limit
+	}
+	return *collectorSeriesLimit
+}
+
+// collectorDurations is a kingpin.Value holding durations keyed by collector
+// name, set from repeated <collector>=<duration> flag values.
+type collectorDurations map[string]time.Duration
+
+func collectorDurationsFlag(s kingpin.Settings) *collectorDurations {
+	d := make(collectorDurations)
+	s.SetValue(&d)
+	return &d
+}
+
+func (d *collectorDurations) Set(value string) error {
+	name, duration, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected <collector>=<duration>, got %q", value)
+	}
+	if _, ok := factories[name]; !ok {
+		return fmt.Errorf("unknown collector: %s", name)
+	}
+	v, err := time.ParseDuration(duration)
+	if err != nil {
+		return fmt.Errorf("invalid duration for collector %s: %w", name, err)
+	}
+	(*d)[name] = v
+	return nil
+}
+
+func (d *collectorDurations) String() string {
+	pairs := make([]string, 0, len(*d))
+	for name, v := range *d {
+		pairs = append(pairs, name+"="+v.String())
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (d *collectorDurations) IsCumulative() bool {
+	return true
+}
+
+// collectorCounts is a kingpin.Value holding counts keyed by collector name,
+// set from repeated <collector>=<count> flag values.
+type collectorCounts map[string]int
+
+func collectorCountsFlag(s kingpin.Settings) *collectorCounts {
+	c := make(collectorCounts)
+	s.SetValue(&c)
+	return &c
+}
+
+func (c *collectorCounts) Set(value string) error {
+	name, count, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected <collector>=<count>, got %q", value)
+	}
+	if _, ok := factories[name]; !ok {
+		return fmt.Errorf("unknown collector: %s", name)
+	}
+	v, err := strconv.Atoi(count)
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid count for collector %s: %q", name, count)
+	}
+	(*c)[name] = v
+	return nil
+}
+
+func (c *collectorCounts) String() string {
+	pairs := make([]string, 0, len(*c))
+	for name, v := range *c {
+		pairs = append(pairs, name+"="+strconv.Itoa(v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (c *collectorCounts) IsCumulative() bool {
+	return true
 }
 
 // Collector is the interface a collector has to implement.
@@ -182,6 +767,15 @@ type Collector interface {
 	Update(ch chan<- prometheus.Metric) error
 }
 
+// ContextCollector is implemented by collectors that can stop their work
+// when the scrape is cancelled, e.g. because it timed out or the client went
+// away. NodeCollector prefers UpdateContext over Update when available.
+type ContextCollector interface {
+	Collector
+	// Like Update, but returns early once ctx is done.
+	UpdateContext(ctx context.Context, ch chan<- prometheus.Metric) error
+}
+
 type typedDesc struct {
 	desc      *prometheus.Desc
 	valueType prometheus.ValueType
@@ -0,0 +1,228 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var testGaugeDesc = prometheus.NewDesc("node_test_value", "Test value.", nil, nil)
+
+type testCollector struct {
+	block chan struct{}
+}
+
+func (c testCollector) Update(ch chan<- prometheus.Metric) error {
+	if c.block != nil {
+		<-c.block
+	}
+	ch <- prometheus.MustNewConstMetric(testGaugeDesc, prometheus.GaugeValue, 1)
+	return nil
+}
+
+func TestNodeCollectorTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	overrides := *collectorTimeoutOverrides
+	defer func() { *collectorTimeoutOverrides = overrides }()
+	*collectorTimeoutOverrides = collectorDurations{"hung": 50 * time.Millisecond}
+
+	nc := &NodeCollector{
+		Collectors: map[string]Collector{
+			"fast": testCollector{},
+			"hung": testCollector{block: block},
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	want := `# HELP node_scrape_collector_success node_exporter: Whether a collector succeeded.
+# TYPE node_scrape_collector_success gauge
+node_scrape_collector_success{collector="fast"} 1
+node_scrape_collector_success{collector="hung"} 0
+# HELP node_scrape_collector_timeout node_exporter: Whether a collector was abandoned because it exceeded its timeout.
+# TYPE node_scrape_collector_timeout gauge
+node_scrape_collector_timeout{collector="fast"} 0
+node_scrape_collector_timeout{collector="hung"} 1
+# HELP node_test_value Test value.
+# TYPE node_test_value gauge
+node_test_value 1
+`
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(nc.WithContext(context.Background()))
+	err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"node_scrape_collector_success", "node_scrape_collector_timeout", "node_test_value")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+var testSeriesDesc = prometheus.NewDesc("node_test_series", "Test series.", []string{"collector", "i"}, nil)
+
+type testSeriesCollector struct {
+	name  string
+	count int
+}
+
+func (c testSeriesCollector) Update(ch chan<- prometheus.Metric) error {
+	// In reverse, for the series kept below the limit not to be the first
+	// ones sent.
+	for i := c.count - 1; i >= 0; i-- {
+		ch <- prometheus.MustNewConstMetric(testSeriesDesc, prometheus.GaugeValue, 1, c.name, strconv.Itoa(i))
+	}
+	return nil
+}
+
+func TestNodeCollectorSeriesLimit(t *testing.T) {
+	limit, overrides := *collectorSeriesLimit, *collectorSeriesLimitOverrides
+	defer func() { *collectorSeriesLimit, *collectorSeriesLimitOverrides = limit, overrides }()
+	*collectorSeriesLimit = 3
+	*collectorSeriesLimitOverrides = collectorCounts{"unlimited": 0}
+
+	nc := &NodeCollector{
+		Collectors: map[string]Collector{
+			"large":     testSeriesCollector{"large", 5},
+			"small":     testSeriesCollector{"small", 3},
+			"unlimited": testSeriesCollector{"unlimited", 5},
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	want := `# HELP node_scrape_collector_series node_exporter: Number of metrics a collector exposed, histograms and summaries counting once.
+# TYPE node_scrape_collector_series gauge
+node_scrape_collector_series{collector="large"} 3
+node_scrape_collector_series{collector="small"} 3
+node_scrape_collector_series{collector="unlimited"} 5
+# HELP node_scrape_collector_truncated node_exporter: Whether metrics of a collector were dropped because it exceeded its series limit.
+# TYPE node_scrape_collector_truncated gauge
+node_scrape_collector_truncated{collector="large"} 1
+node_scrape_collector_truncated{collector="small"} 0
+node_scrape_collector_truncated{collector="unlimited"} 0
+`
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(nc)
+	err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"node_scrape_collector_series", "node_scrape_collector_truncated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := testutil.GatherAndCount(reg, "node_test_series"); err != nil || got != 11 {
+		t.Errorf("want 11 test series, got %d (err %v)", got, err)
+	}
+
+	// The first series in order are kept, whatever order they were sent in.
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, mf := range mfs {
+		if mf.GetName() != "node_test_series" {
+			continue
+		}
+		for _, m := range mf.Metric {
+			if m.Label[0].GetValue() == "large" {
+				kept = append(kept, m.Label[1].GetValue())
+			}
+		}
+	}
+	if !slices.Equal(kept, []string{"0", "1", "2"}) {
+		t.Errorf("want series 0, 1 and 2 of the large collector kept, got %v", kept)
+	}
+}
+
+type testContextCollector struct {
+	testCollector
+	returned chan struct{}
+}
+
+func (c testContextCollector) UpdateContext(ctx context.Context, _ chan<- prometheus.Metric) error {
+	defer close(c.returned)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestNodeCollectorCancelsContextCollector(t *testing.T) {
+	c := testContextCollector{returned: make(chan struct{})}
+	nc := &NodeCollector{
+		Collectors: map[string]Collector{"slow": c},
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	want := `# HELP node_scrape_collector_timeout node_exporter: Whether a collector was abandoned because it exceeded its timeout.
+# TYPE node_scrape_collector_timeout gauge
+node_scrape_collector_timeout{collector="slow"} 1
+`
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(nc.WithContext(ctx))
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "node_scrape_collector_timeout"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-c.returned:
+	case <-time.After(time.Second):
+		t.Fatal("UpdateContext did not return after the scrape context was done")
+	}
+}
+
+func TestCollectorDurations(t *testing.T) {
+	d := make(collectorDurations)
+	for _, v := range []string{"textfile=2s", "cpu=500ms"} {
+		if err := d.Set(v); err != nil {
+			t.Fatalf("unexpected error setting %q: %s", v, err)
+		}
+	}
+	if want, got := "cpu=500ms,textfile=2s", d.String(); want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	for _, v := range []string{"textfile", "nonexistent=1s", "textfile=soon"} {
+		if err := d.Set(v); err == nil {
+			t.Errorf("expected error setting %q", v)
+		}
+	}
+}
+
+func TestRequestLogHandler(t *testing.T) {
+	var b strings.Builder
+	logger := slog.New(requestLogHandler{slog.NewTextHandler(&b, nil)})
+	logger.Info("Parsed flag --collector.netdev.device-include", "flag", "^tun")
+	if b.Len() != 0 {
+		t.Errorf("want info demoted below the default level, got %q", b.String())
+	}
+	logger.With("collector", "netdev").Warn("warning")
+	if got := b.String(); !strings.Contains(got, "level=WARN msg=warning collector=netdev") {
+		t.Errorf("want warning logged, got %q", got)
+	}
+
+	b.Reset()
+	logger = slog.New(requestLogHandler{slog.NewTextHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug})})
+	logger.Info("Parsed flag --collector.netdev.device-include", "flag", "^tun")
+	if got := b.String(); !strings.Contains(got, "level=DEBUG") {
+		t.Errorf("want info logged at debug, got %q", got)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/common/model"
+)
+
+// Config is the configuration of a single collector as read from the
+// configuration file. Options are named like the collector's command-line
+// flags without the "collector.<name>." prefix, e.g. "unit-include" for
+// --collector.systemd.unit-include.
+type Config struct {
+	Enabled         *bool          `yaml:"enabled,omitempty"`
+	ScrapeTimeout   model.Duration `yaml:"scrape_timeout,omitempty"`
+	SeriesLimit     int            `yaml:"series_limit,omitempty"`
+	CollectInterval model.Duration `yaml:"collect_interval,omitempty"`
+	Options         map[string]any `yaml:"options,omitempty"`
+	// MetricRelabelConfigs are applied to the metrics of the collector,
+	// before the ones of the whole NodeCollector.
+	MetricRelabelConfigs []*RelabelConfig `yaml:"metric_relabel_configs,omitempty"`
+}
+
+// settings is a snapshot of everything configuring the collectors: the values
+// of their command-line flags, keyed by flag name, the per-collector
+// timeouts, series limits, collection intervals and relabeling rules, and the
+// relabeling rules of the whole NodeCollector.
+type settings struct {
+	flags     map[string][]string
+	timeouts  collectorDurations
+	limits    collectorCounts
+	intervals collectorDurations
+	relabel   map[string][]*RelabelConfig
+	// nodeRelabel are the relabeling rules of the whole NodeCollector.
+	nodeRelabel []*RelabelConfig
+}
+
+var (
+	configMtx sync.Mutex
+	// collectorRelabelConfigs and nodeRelabelConfigs hold the relabeling
+	// rules of the collectors and of the whole NodeCollector. They can only
+	// be set through the configuration file.
+	collectorRelabelConfigs = map[string][]*RelabelConfig{}
+	nodeRelabelConfigs      []*RelabelConfig
+	// cliSettings holds the settings given on the command line. They are
+	// captured on the first ApplyConfig and are the base every
+	// configuration is applied on.
+	cliSettings *settings
+)
+
+// ApplyConfig resets the collector settings to the ones given on the command
+// line and applies configs on top, keyed by collector name, along with the
+// relabeling rules applied to all metrics of a NodeCollector. Collectors whose
+// settings changed are discarded, so that the next NewNodeCollector creates
+// them anew. If an error is returned, the previous settings are left in place.
+func ApplyConfig(configs map[string]Config, relabelConfigs []*RelabelConfig) (err error) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	initiatedCollectorsMtx.Lock()
+	defer initiatedCollectorsMtx.Unlock()
+
+	if cliSettings == nil {
+		cliSettings = currentSettings()
+	}
+	previous := currentSettings()
+	defer func() {
+		if err != nil {
+			restoreSettings(previous)
+		}
+	}()
+
+	restoreSettings(cliSettings)
+	nodeRelabelConfigs = relabelConfigs
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		if err := applyCollectorConfig(name, configs[name]); err != nil {
+			return fmt.Errorf("collector %s: %w", name, err)
+		}
+	}
+
+	current := currentSettings()
+	for name, c := range initiatedCollectors {
+		if previous.signature(name) == current.signature(name) {
+			continue
+		}
+		if closer, ok := c.(interface{ Close() }); ok {
+			closer.Close()
+		}
+		delete(initiatedCollectors, name)
+	}
+	return nil
+}
+
+func applyCollectorConfig(name string, cfg Config) error {
+	if _, ok := factories[name]; !ok {
+		return fmt.Errorf("unknown collector")
+	}
+	if cfg.Enabled != nil {
+		*collectorState[name] = *cfg.Enabled
+	}
+	if cfg.ScrapeTimeout > 0 {
+		(*collectorTimeoutOverrides)[name] = time.Duration(cfg.ScrapeTimeout)
+	}
+	if cfg.SeriesLimit > 0 {
+		(*collectorSeriesLimitOverrides)[name] = cfg.SeriesLimit
+	}
+	if cfg.CollectInterval > 0 {
+		(*collectorIntervals)[name] = time.Duration(cfg.CollectInterval)
+	}
+	if len(cfg.MetricRelabelConfigs) > 0 {
+		collectorRelabelConfigs[name] = cfg.MetricRelabelConfigs
+	}
+	for _, option := range slices.Sorted(maps.Keys(cfg.Options)) {
+		flag := kingpin.CommandLine.GetFlag(fmt.Sprintf("collector.%s.%s", name, option))
+		if flag == nil {
+			return fmt.Errorf("unknown option %q", option)
+		}
+		values, err := optionValues(cfg.Options[option])
+		if err != nil {
+			return fmt.Errorf("option %q: %w", option, err)
+		}
+		if err := writeFlag(flag.Model().Value, values); err != nil {
+			return fmt.Errorf("option %q: %w", option, err)
+		}
+	}
+	return nil
+}
+
+// optionValues converts an option value read from YAML into flag values.
+func optionValues(v any) ([]string, error) {
+	list, ok := v.([]any)
+	if !ok {
+		list = []any{v}
+	}
+	values := make([]string, 0, len(list))
+	for _, e := range list {
+		switch e := e.(type) {
+		case nil:
+			values = append(values, "")
+		case string, bool, int, float64:
+			values = append(values, fmt.Sprint(e))
+		default:
+			return nil, fmt.Errorf("unsupported value %v", e)
+		}
+	}
+	return values, nil
+}
+
+// collectorFlags returns the flags belonging to registered collectors, i.e.
+// --collector.<name> and --collector.<name>.*.
+func collectorFlags() []*kingpin.FlagModel {
+	var flags []*kingpin.FlagModel
+	for _, f := range kingpin.CommandLine.Model().Flags {
+		if name, ok := strings.CutPrefix(f.Name, "collector."); ok {
+			name, _, _ = strings.Cut(name, ".")
+			if _, ok := factories[name]; ok {
+				flags = append(flags, f)
+			}
+		}
+	}
+	return flags
+}
+
+func currentSettings() *settings {
+	s := &settings{
+		flags:     make(map[string][]string),
+		timeouts:  maps.Clone(*collectorTimeoutOverrides),
+		limits:    maps.Clone(*collectorSeriesLimitOverrides),
+		intervals: maps.Clone(*collectorIntervals),
+		relabel:   maps.Clone(collectorRelabelConfigs),
+		// The rules are never modified, only replaced.
+		nodeRelabel: nodeRelabelConfigs,
+	}
+	for _, f := range collectorFlags() {
+		s.flags[f.Name] = readFlag(f.Value)
+	}
+	return s
+}
+
+func restoreSettings(s *settings) {
+	for _, f := range collectorFlags() {
+		if values, ok := s.flags[f.Name]; ok {
+			// The values were read from the flag, so they are valid.
+			_ = writeFlag(f.Value, values)
+		}
+	}
+	*collectorTimeoutOverrides = maps.Clone(s.timeouts)
+	*collectorSeriesLimitOverrides = maps.Clone(s.limits)
+	*collectorIntervals = maps.Clone(s.intervals)
+	collectorRelabelConfigs = maps.Clone(s.relabel)
+	nodeRelabelConfigs = s.nodeRelabel
+}
+
+// signature identifies the settings a collector is created with. Timeouts
+// are only fixed at creation for collectors running in the background.
+func (s *settings) signature(name string) string {
+	var b strings.Builder
+	prefix := "collector." + name + "."
+	for _, flag := range slices.Sorted(maps.Keys(s.flags)) {
+		if strings.HasPrefix(flag, prefix) {
+			fmt.Fprintf(&b, "%s=%q;", flag, s.flags[flag])
+		}
+	}
+	if interval := s.intervals[name]; interval > 0 {
+		timeout, ok := s.timeouts[name]
+		if !ok {
+			timeout = *collectorTimeout
+		}
+		fmt.Fprintf(&b, "interval=%s;timeout=%s", interval, timeout)
+	}
+	return b.String()
+}
+
+// readFlag returns the values of a flag. Repeatable flags are backed by a
+// string slice, all others are read as their string representation.
+func readFlag(v kingpin.Value) []string {
+	if p, ok := cumulativeStrings(v); ok {
+		return slices.Clone(*p)
+	}
+	return []string{v.String()}
+}
+
+// writeFlag sets the values of a flag, replacing the ones it had before.
+func writeFlag(v kingpin.Value, values []string) error {
+	if p, ok := cumulativeStrings(v); ok {
+		*p = nil
+		for _, value := range values {
+			if err := v.Set(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(values) != 1 {
+		return fmt.Errorf("expected a single value, got %d", len(values))
+	}
+	return v.Set(values[0])
+}
+
+func cumulativeStrings(v kingpin.Value) (*[]string, bool) {
+	if c, ok := v.(interface{ IsCumulative() bool }); !ok || !c.IsCumulative() {
+		return nil, false
+	}
+	g, ok := v.(kingpin.Getter)
+	if !ok {
+		return nil, false
+	}
+	p, ok := g.Get().(*[]string)
+	return p, ok
+}
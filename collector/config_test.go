@@ -0,0 +1,153 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nontp
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/common/model"
+)
+
+func TestApplyConfig(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	defer ApplyConfig(nil, nil)
+
+	server := kingpin.CommandLine.GetFlag("collector.ntp.server").Model().Value
+	defaultServer := server.String()
+	enabled := true
+
+	err := ApplyConfig(map[string]Config{
+		"ntp": {
+			Enabled:       &enabled,
+			ScrapeTimeout: model.Duration(2 * time.Second),
+			Options:       map[string]any{"server": "192.0.2.1", "server-port": 1123},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !*collectorState["ntp"] {
+		t.Error("ntp collector not enabled")
+	}
+	if want, got := 2*time.Second, timeoutFor("ntp"); want != got {
+		t.Errorf("want timeout %s, got %s", want, got)
+	}
+	if want, got := "192.0.2.1", server.String(); want != got {
+		t.Errorf("want server %q, got %q", want, got)
+	}
+
+	// A failing configuration leaves the previous one in place.
+	for name, c := range map[string]Config{
+		"unknown collector": {},
+		"ntp":               {Options: map[string]any{"nonexistent": 1}},
+	} {
+		if err := ApplyConfig(map[string]Config{name: c}, nil); err == nil {
+			t.Errorf("expected error applying config for %q", name)
+		}
+		if want, got := "192.0.2.1", server.String(); want != got {
+			t.Errorf("want server %q, got %q", want, got)
+		}
+	}
+
+	// Options not present in a configuration revert to the command line.
+	if err := ApplyConfig(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if *collectorState["ntp"] {
+		t.Error("ntp collector still enabled")
+	}
+	if want, got := defaultServer, server.String(); want != got {
+		t.Errorf("want server %q, got %q", want, got)
+	}
+	if _, ok := (*collectorTimeoutOverrides)["ntp"]; ok {
+		t.Error("ntp timeout override not reverted")
+	}
+}
+
+func TestWriteFlag(t *testing.T) {
+	app := kingpin.New("test", "")
+	list := app.Flag("list", "").Strings()
+	single := app.Flag("single", "").Bool()
+	if _, err := app.Parse([]string{"--list=a", "--list=b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	listValue := app.GetFlag("list").Model().Value
+	if err := writeFlag(listValue, []string{"c"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFlag(listValue); len(got) != 1 || got[0] != "c" || len(*list) != 1 {
+		t.Errorf("want [c], got %q", got)
+	}
+
+	singleValue := app.GetFlag("single").Model().Value
+	if err := writeFlag(singleValue, []string{"true", "false"}); err == nil {
+		t.Error("expected error writing several values to a single value flag")
+	}
+	if err := writeFlag(singleValue, []string{"true"}); err != nil || !*single {
+		t.Errorf("want single flag set, got %v (err %v)", *single, err)
+	}
+}
+
+func TestNewProfileCollector(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	server := kingpin.CommandLine.GetFlag("collector.ntp.server").Model().Value
+	defaultServer := server.String()
+
+	nc, err := NewProfileCollector(slog.New(slog.NewTextHandler(io.Discard, nil)), map[string]Config{
+		"ntp": {
+			ScrapeTimeout: model.Duration(time.Second),
+			Options:       map[string]any{"server": "127.0.0.2"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	c, ok := nc.Collectors["ntp"].(*ntpCollector)
+	if !ok {
+		t.Fatalf("want ntp collector, got %v", nc.Collectors)
+	}
+	if want, got := "127.0.0.2", c.server; want != got {
+		t.Errorf("want profile server %q, got %q", want, got)
+	}
+	if want, got := time.Second, nc.timeout("ntp"); want != got {
+		t.Errorf("want profile timeout %s, got %s", want, got)
+	}
+	if len(nc.owned) != 1 {
+		t.Errorf("want the ntp collector owned by the profile, got %d owned collectors", len(nc.owned))
+	}
+	if want, got := defaultServer, server.String(); want != got {
+		t.Errorf("want global server %q restored, got %q", want, got)
+	}
+
+	// Invalid options fail the profile.
+	_, err = NewProfileCollector(slog.New(slog.NewTextHandler(io.Discard, nil)), map[string]Config{
+		"ntp": {Options: map[string]any{"server": "192.0.2.1"}},
+	})
+	if err == nil {
+		t.Error("expected error creating a profile with a non-local NTP server")
+	}
+}
@@ -93,8 +93,8 @@ func ioctl(fd int, nr uint, typ byte, size uintptr, retptr unsafe.Pointer) error
 	return nil
 }
 
-func readSysmonProperties() (sysmonProperties, error) {
-	fd, err := unix.Open(rootfsFilePath("/dev/sysmon"), unix.O_RDONLY, 0)
+func readSysmonProperties(paths Paths) (sysmonProperties, error) {
+	fd, err := unix.Open(paths.rootfsFilePath("/dev/sysmon"), unix.O_RDONLY, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -143,12 +143,12 @@ func convertTemperatures(prop sysmonProperty, res map[int]float64) error {
 	return nil
 }
 
-func getCPUTemperatures() (map[int]float64, error) {
+func getCPUTemperatures(paths Paths) (map[int]float64, error) {
 
 	res := make(map[int]float64)
 
 	// Read all properties
-	props, err := readSysmonProperties()
+	props, err := readSysmonProperties(paths)
 	if err != nil {
 		return res, err
 	}
@@ -215,6 +215,7 @@ func getCPUTimes() ([]cputime, error) {
 type statCollector struct {
 	cpu    typedDesc
 	temp   typedDesc
+	paths  Paths
 	logger *slog.Logger
 }
 
@@ -231,6 +232,7 @@ func NewStatCollector(logger *slog.Logger) (Collector, error) {
 			"CPU temperature",
 			[]string{"cpu"}, nil,
 		), prometheus.GaugeValue},
+		paths:  currentPaths(),
 		logger: logger,
 	}, nil
 }
@@ -254,7 +256,7 @@ func (c *statCollector) Update(ch chan<- prometheus.Metric) error {
 		return err
 	}
 
-	cpuTemperatures, err := getCPUTemperatures()
+	cpuTemperatures, err := getCPUTemperatures(c.paths)
 	if err != nil {
 		return err
 	}
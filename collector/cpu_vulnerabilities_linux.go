@@ -34,18 +34,22 @@ var (
 	)
 )
 
-type cpuVulnerabilitiesCollector struct{}
+type cpuVulnerabilitiesCollector struct {
+	paths Paths
+}
 
 func init() {
 	registerCollector(cpuVulnerabilitiesCollectorSubsystem, defaultDisabled, NewVulnerabilitySysfsCollector)
 }
 
 func NewVulnerabilitySysfsCollector(_ *slog.Logger) (Collector, error) {
-	return &cpuVulnerabilitiesCollector{}, nil
+	return &cpuVulnerabilitiesCollector{
+		paths: currentPaths(),
+	}, nil
 }
 
 func (v *cpuVulnerabilitiesCollector) Update(ch chan<- prometheus.Metric) error {
-	fs, err := sysfs.NewFS(*sysPath)
+	fs, err := sysfs.NewFS(v.paths.sysMountPoint())
 	if err != nil {
 		return fmt.Errorf("failed to open sysfs: %w", err)
 	}
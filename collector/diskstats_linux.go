@@ -254,7 +254,10 @@ func NewDiskstatsCollector(logger *slog.Logger) (Collector, error) {
 	if stat, err := os.Stat(*udevDataPath); err != nil || !stat.IsDir() {
 		logger.Error("Failed to open directory, disabling udev device properties", "path", *udevDataPath)
 	} else {
-		collector.getUdevDeviceProperties = getUdevDeviceProperties
+		paths := currentPaths()
+		collector.getUdevDeviceProperties = func(major, minor uint32) (udevInfo, error) {
+			return getUdevDeviceProperties(paths, major, minor)
+		}
 	}
 
 	return &collector, nil
@@ -397,8 +400,8 @@ func rotationalLabel(fs blockdevice.FS, dev string) string {
 	return "0"
 }
 
-func getUdevDeviceProperties(major, minor uint32) (udevInfo, error) {
-	filename := udevDataFilePath(fmt.Sprintf("b%d:%d", major, minor))
+func getUdevDeviceProperties(paths Paths, major, minor uint32) (udevInfo, error) {
+	filename := paths.udevDataFilePath(fmt.Sprintf("b%d:%d", major, minor))
 
 	data, err := os.Open(filename)
 	if err != nil {
@@ -77,6 +77,7 @@ type drbdCollector struct {
 	numerical  map[string]drbdNumericalMetric
 	stringPair map[string]drbdStringPairMetric
 	connected  *prometheus.Desc
+	paths      Paths
 	logger     *slog.Logger
 }
 
@@ -180,12 +181,13 @@ func newDRBDCollector(logger *slog.Logger) (Collector, error) {
 			[]string{"device"},
 			nil,
 		),
+		paths:  currentPaths(),
 		logger: logger,
 	}, nil
 }
 
 func (c *drbdCollector) Update(ch chan<- prometheus.Metric) error {
-	statsFile := procFilePath("drbd")
+	statsFile := c.paths.procFilePath("drbd")
 	file, err := os.Open(statsFile)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -37,6 +37,7 @@ var (
 )
 
 type edacCollector struct {
+	paths  Paths
 	logger *slog.Logger
 }
 
@@ -80,6 +81,7 @@ var (
 // NewEdacCollector returns a new Collector exposing edac stats.
 func NewEdacCollector(logger *slog.Logger) (Collector, error) {
 	return &edacCollector{
+		paths:  currentPaths(),
 		logger: logger,
 	}, nil
 }
@@ -98,7 +100,7 @@ func edacDimmLabel(csrow, channel string) string {
 }
 
 func (c *edacCollector) Update(ch chan<- prometheus.Metric) error {
-	memControllers, err := filepath.Glob(sysFilePath("devices/system/edac/mc/mc[0-9]*"))
+	memControllers, err := filepath.Glob(c.paths.sysFilePath("devices/system/edac/mc/mc[0-9]*"))
 	if err != nil {
 		return err
 	}
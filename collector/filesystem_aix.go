@@ -16,6 +16,8 @@
 package collector
 
 import (
+	"context"
+
 	"github.com/power-devops/perfstat"
 )
 
@@ -24,8 +26,15 @@ const (
 	defFSTypesExcluded     = "^procfs$"
 )
 
+// filesystemConfig holds the platform specific flags, of which there are none.
+type filesystemConfig struct{}
+
+func newFilesystemConfig() filesystemConfig {
+	return filesystemConfig{}
+}
+
 // Expose filesystem fullness.
-func (c *filesystemCollector) GetStats() (stats []filesystemStats, err error) {
+func (c *filesystemCollector) GetStats(_ context.Context) (stats []filesystemStats, err error) {
 	fsStat, err := perfstat.FileSystemStat()
 	if err != nil {
 		return nil, err
@@ -16,6 +16,7 @@
 package collector
 
 import (
+	"context"
 	"errors"
 	"unsafe"
 )
@@ -34,8 +35,15 @@ const (
 	readOnly               = 0x1 // MNT_RDONLY
 )
 
+// filesystemConfig holds the platform specific flags, of which there are none.
+type filesystemConfig struct{}
+
+func newFilesystemConfig() filesystemConfig {
+	return filesystemConfig{}
+}
+
 // Expose filesystem fullness.
-func (c *filesystemCollector) GetStats() (stats []filesystemStats, err error) {
+func (c *filesystemCollector) GetStats(_ context.Context) (stats []filesystemStats, err error) {
 	var mntbuf *C.struct_statfs
 	count := C.getmntinfo(&mntbuf, C.MNT_NOWAIT)
 	if count == 0 {
@@ -66,7 +74,7 @@ func (c *filesystemCollector) GetStats() (stats []filesystemStats, err error) {
 		stats = append(stats, filesystemStats{
 			labels: filesystemLabels{
 				device:     device,
-				mountPoint: rootfsStripPrefix(mountpoint),
+				mountPoint: c.paths.rootfsStripPrefix(mountpoint),
 				fsType:     fstype,
 			},
 			size:      float64(mnt[i].f_blocks) * float64(mnt[i].f_bsize),
@@ -16,6 +16,7 @@
 package collector
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -76,6 +77,8 @@ type filesystemCollector struct {
 	purgeableDesc                 *prometheus.Desc
 	roDesc, deviceErrorDesc       *prometheus.Desc
 	mountInfoDesc                 *prometheus.Desc
+	config                        filesystemConfig
+	paths                         Paths
 	logger                        *slog.Logger
 }
 
@@ -92,7 +95,8 @@ type filesystemStats struct {
 }
 
 func init() {
-	registerCollector("filesystem", defaultEnabled, NewFilesystemCollector)
+	registerCollector("filesystem", defaultEnabled, NewFilesystemCollector,
+		"mount-points-include", "mount-points-exclude", "fs-types-include", "fs-types-exclude")
 }
 
 // NewFilesystemCollector returns a new Collector exposing filesystems stats.
@@ -176,12 +180,18 @@ func NewFilesystemCollector(logger *slog.Logger) (Collector, error) {
 		roDesc:           roDesc,
 		deviceErrorDesc:  deviceErrorDesc,
 		mountInfoDesc:    mountInfoDesc,
+		config:           newFilesystemConfig(),
+		paths:            currentPaths(),
 		logger:           logger,
 	}, nil
 }
 
 func (c *filesystemCollector) Update(ch chan<- prometheus.Metric) error {
-	stats, err := c.GetStats()
+	return c.UpdateContext(context.Background(), ch)
+}
+
+func (c *filesystemCollector) UpdateContext(ctx context.Context, ch chan<- prometheus.Metric) error {
+	stats, err := c.GetStats(ctx)
 	if err != nil {
 		return err
 	}
@@ -16,6 +16,8 @@
 package collector
 
 import (
+	"context"
+
 	"golang.org/x/sys/unix"
 )
 
@@ -24,8 +26,15 @@ const (
 	defFSTypesExcluded     = "^devfs$"
 )
 
+// filesystemConfig holds the platform specific flags, of which there are none.
+type filesystemConfig struct{}
+
+func newFilesystemConfig() filesystemConfig {
+	return filesystemConfig{}
+}
+
 // Expose filesystem fullness.
-func (c *filesystemCollector) GetStats() ([]filesystemStats, error) {
+func (c *filesystemCollector) GetStats(_ context.Context) ([]filesystemStats, error) {
 	n, err := unix.Getfsstat(nil, unix.MNT_NOWAIT)
 	if err != nil {
 		return nil, err
@@ -63,7 +72,7 @@ func (c *filesystemCollector) GetStats() ([]filesystemStats, error) {
 		stats = append(stats, filesystemStats{
 			labels: filesystemLabels{
 				device:     device,
-				mountPoint: rootfsStripPrefix(mountpoint),
+				mountPoint: c.paths.rootfsStripPrefix(mountpoint),
 				fsType:     fstype,
 			},
 			size:      float64(fs.Blocks) * float64(fs.Bsize),
@@ -16,6 +16,7 @@
 package collector
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -46,9 +47,21 @@ var statWorkerCount = kingpin.Flag("collector.filesystem.stat-workers",
 var stuckMounts = make(map[string]struct{})
 var stuckMountsMtx = &sync.Mutex{}
 
-// GetStats returns filesystem stats.
-func (c *filesystemCollector) GetStats() ([]filesystemStats, error) {
-	mps, err := mountPointDetails(c.logger)
+// filesystemConfig holds the platform specific flags, captured at creation.
+type filesystemConfig struct {
+	mountTimeout    time.Duration
+	statWorkerCount int
+}
+
+func newFilesystemConfig() filesystemConfig {
+	return filesystemConfig{mountTimeout: *mountTimeout, statWorkerCount: *statWorkerCount}
+}
+
+// GetStats returns filesystem stats. It stops handing out mount points to
+// the stat workers once ctx is done; statfs calls already in progress are
+// left to the stuck mount watcher.
+func (c *filesystemCollector) GetStats(ctx context.Context) ([]filesystemStats, error) {
+	mps, err := mountPointDetails(c.paths, c.logger)
 	if err != nil {
 		return nil, err
 	}
@@ -57,17 +70,25 @@ func (c *filesystemCollector) GetStats() ([]filesystemStats, error) {
 	statChan := make(chan filesystemStats)
 	wg := sync.WaitGroup{}
 
-	workerCount := max(*statWorkerCount, 1)
+	workerCount := max(c.config.statWorkerCount, 1)
 
 	for range workerCount {
 		wg.Go(func() {
 			for labels := range labelChan {
-				statChan <- c.processStat(labels)
+				select {
+				case statChan <- c.processStat(labels):
+				case <-ctx.Done():
+				}
 			}
 		})
 	}
 
 	go func() {
+		defer func() {
+			close(labelChan)
+			wg.Wait()
+			close(statChan)
+		}()
 		for _, labels := range mps {
 			if c.mountPointFilter.ignored(labels.mountPoint) {
 				c.logger.Debug("Ignoring mount point", "mountpoint", labels.mountPoint)
@@ -80,28 +101,37 @@ func (c *filesystemCollector) GetStats() ([]filesystemStats, error) {
 
 			stuckMountsMtx.Lock()
 			if _, ok := stuckMounts[labels.mountPoint]; ok {
+				stuckMountsMtx.Unlock()
 				labels.deviceError = "mountpoint timeout"
-				stats = append(stats, filesystemStats{
-					labels:      labels,
-					deviceError: 1,
-				})
 				c.logger.Debug("Mount point is in an unresponsive state", "mountpoint", labels.mountPoint)
-				stuckMountsMtx.Unlock()
-				continue
+				select {
+				case statChan <- filesystemStats{labels: labels, deviceError: 1}:
+					continue
+				case <-ctx.Done():
+					return
+				}
 			}
-
 			stuckMountsMtx.Unlock()
-			labelChan <- labels
+
+			select {
+			case labelChan <- labels:
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(labelChan)
-		wg.Wait()
-		close(statChan)
 	}()
 
-	for stat := range statChan {
-		stats = append(stats, stat)
+	for {
+		select {
+		case stat, ok := <-statChan:
+			if !ok {
+				return stats, nil
+			}
+			stats = append(stats, stat)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
-	return stats, nil
 }
 
 func (c *filesystemCollector) processStat(labels filesystemLabels) filesystemStats {
@@ -111,10 +141,10 @@ func (c *filesystemCollector) processStat(labels filesystemLabels) filesystemSta
 	}
 
 	success := make(chan struct{})
-	go stuckMountWatcher(labels.mountPoint, success, c.logger)
+	go stuckMountWatcher(labels.mountPoint, c.config.mountTimeout, success, c.logger)
 
 	buf := new(unix.Statfs_t)
-	err := unix.Statfs(rootfsFilePath(labels.mountPoint), buf)
+	err := unix.Statfs(c.paths.rootfsFilePath(labels.mountPoint), buf)
 	stuckMountsMtx.Lock()
 	close(success)
 
@@ -133,7 +163,7 @@ func (c *filesystemCollector) processStat(labels filesystemLabels) filesystemSta
 
 	if err != nil {
 		labels.deviceError = err.Error()
-		c.logger.Debug("Error on statfs() system call", "rootfs", rootfsFilePath(labels.mountPoint), "err", err)
+		c.logger.Debug("Error on statfs() system call", "rootfs", c.paths.rootfsFilePath(labels.mountPoint), "err", err)
 		return filesystemStats{
 			labels:      labels,
 			deviceError: 1,
@@ -155,8 +185,8 @@ func (c *filesystemCollector) processStat(labels filesystemLabels) filesystemSta
 // stuckMountWatcher listens on the given success channel and if the channel closes
 // then the watcher does nothing. If instead the timeout is reached, the
 // mount point that is being watched is marked as stuck.
-func stuckMountWatcher(mountPoint string, success chan struct{}, logger *slog.Logger) {
-	mountCheckTimer := time.NewTimer(*mountTimeout)
+func stuckMountWatcher(mountPoint string, timeout time.Duration, success chan struct{}, logger *slog.Logger) {
+	mountCheckTimer := time.NewTimer(timeout)
 	defer mountCheckTimer.Stop()
 	select {
 	case <-success:
@@ -175,8 +205,8 @@ func stuckMountWatcher(mountPoint string, success chan struct{}, logger *slog.Lo
 	}
 }
 
-func mountPointDetails(logger *slog.Logger) ([]filesystemLabels, error) {
-	fs, err := procfs.NewFS(*procPath)
+func mountPointDetails(paths Paths, logger *slog.Logger) ([]filesystemLabels, error) {
+	fs, err := procfs.NewFS(paths.procMountPoint())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
@@ -190,10 +220,10 @@ func mountPointDetails(logger *slog.Logger) ([]filesystemLabels, error) {
 		return nil, err
 	}
 
-	return parseFilesystemLabels(mountInfo)
+	return parseFilesystemLabels(paths, mountInfo)
 }
 
-func parseFilesystemLabels(mountInfo []*procfs.MountInfo) ([]filesystemLabels, error) {
+func parseFilesystemLabels(paths Paths, mountInfo []*procfs.MountInfo) ([]filesystemLabels, error) {
 	var filesystems []filesystemLabels
 
 	for _, mount := range mountInfo {
@@ -210,7 +240,7 @@ func parseFilesystemLabels(mountInfo []*procfs.MountInfo) ([]filesystemLabels, e
 
 		filesystems = append(filesystems, filesystemLabels{
 			device:       strings.ToValidUTF8(mount.Source, "�"),
-			mountPoint:   strings.ToValidUTF8(rootfsStripPrefix(mount.MountPoint), "�"),
+			mountPoint:   strings.ToValidUTF8(paths.rootfsStripPrefix(mount.MountPoint), "�"),
 			fsType:       strings.ToValidUTF8(mount.FSType, "�"),
 			mountOptions: mountOptionsString(mount.Options),
 			superOptions: mountOptionsString(mount.SuperOptions),
@@ -45,7 +45,7 @@ func Test_parseFilesystemLabelsError(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if _, err := parseFilesystemLabels(tt.in); err == nil {
+			if _, err := parseFilesystemLabels(Paths{}, tt.in); err == nil {
 				t.Fatal("expected an error, but none occurred")
 			}
 		})
@@ -62,7 +62,7 @@ func Test_parseFilesystemLabelsSanitizesInvalidUTF8(t *testing.T) {
 		},
 	}
 
-	got, err := parseFilesystemLabels(in)
+	got, err := parseFilesystemLabels(Paths{}, in)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -165,7 +165,7 @@ func TestMountPointDetails(t *testing.T) {
 		"/var/lib/containers/storage/overlay": "",
 	}
 
-	filesystems, err := mountPointDetails(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	filesystems, err := mountPointDetails(Paths{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
 	if err != nil {
 		t.Log(err)
 	}
@@ -194,7 +194,7 @@ func TestMountsFallback(t *testing.T) {
 		"/": "",
 	}
 
-	filesystems, err := mountPointDetails(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	filesystems, err := mountPointDetails(Paths{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
 	if err != nil {
 		t.Log(err)
 	}
@@ -304,7 +304,7 @@ func TestPathRootfs(t *testing.T) {
 		"/sys/fs/cgroup": "",
 	}
 
-	filesystems, err := mountPointDetails(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	filesystems, err := mountPointDetails(Paths{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
 	if err != nil {
 		t.Log(err)
 	}
@@ -16,6 +16,7 @@
 package collector
 
 import (
+	"context"
 	"fmt"
 	"syscall"
 	"unsafe"
@@ -30,6 +31,13 @@ const (
 	_VFS_MNAMELEN          = 1024
 )
 
+// filesystemConfig holds the platform specific flags, of which there are none.
+type filesystemConfig struct{}
+
+func newFilesystemConfig() filesystemConfig {
+	return filesystemConfig{}
+}
+
 /*
  * Go uses the NetBSD 9 ABI and thus syscall.SYS_GETVFSSTAT is compat_90_getvfsstat.
  * We have to declare struct statvfs90 because it is not included in the unix package.
@@ -70,7 +78,7 @@ type statvfs90 struct {
 	cgo_pad [4]byte
 }
 
-func (c *filesystemCollector) GetStats() (stats []filesystemStats, err error) {
+func (c *filesystemCollector) GetStats(_ context.Context) (stats []filesystemStats, err error) {
 	var mnt []statvfs90
 	if syscall.SYS_GETVFSSTAT != 356 /* compat_90_getvfsstat */ {
 		/*
@@ -16,6 +16,8 @@
 package collector
 
 import (
+	"context"
+
 	"golang.org/x/sys/unix"
 )
 
@@ -24,8 +26,15 @@ const (
 	defFSTypesExcluded     = "^devfs$"
 )
 
+// filesystemConfig holds the platform specific flags, of which there are none.
+type filesystemConfig struct{}
+
+func newFilesystemConfig() filesystemConfig {
+	return filesystemConfig{}
+}
+
 // Expose filesystem fullness.
-func (c *filesystemCollector) GetStats() (stats []filesystemStats, err error) {
+func (c *filesystemCollector) GetStats(_ context.Context) (stats []filesystemStats, err error) {
 	var mnt []unix.Statfs_t
 	size, err := unix.Getfsstat(mnt, unix.MNT_NOWAIT)
 	if err != nil {
@@ -55,6 +55,7 @@ func init() {
 type hwMonCollector struct {
 	deviceFilter deviceFilter
 	sensorFilter deviceFilter
+	paths        Paths
 	logger       *slog.Logger
 }
 
@@ -63,6 +64,7 @@ type hwMonCollector struct {
 func NewHwMonCollector(logger *slog.Logger) (Collector, error) {
 
 	return &hwMonCollector{
+		paths:        currentPaths(),
 		logger:       logger,
 		deviceFilter: newDeviceFilter(*collectorHWmonChipExclude, *collectorHWmonChipInclude),
 		sensorFilter: newDeviceFilter(*collectorHWmonSensorExclude, *collectorHWmonSensorInclude),
@@ -444,7 +446,7 @@ func (c *hwMonCollector) Update(ch chan<- prometheus.Metric) error {
 	// Step 1: scan /sys/class/hwmon, resolve all symlinks and call
 	//         updateHwmon for each folder.
 
-	hwmonPathName := filepath.Join(sysFilePath("class"), "hwmon")
+	hwmonPathName := filepath.Join(c.paths.sysFilePath("class"), "hwmon")
 
 	hwmonFiles, err := os.ReadDir(hwmonPathName)
 	if err != nil {
@@ -24,6 +24,7 @@ import (
 
 type interruptsCollector struct {
 	desc         typedDesc
+	paths        Paths
 	logger       *slog.Logger
 	nameFilter   deviceFilter
 	includeZeros bool
@@ -47,6 +48,7 @@ func NewInterruptsCollector(logger *slog.Logger) (Collector, error) {
 			"Interrupt details.",
 			interruptLabelNames, nil,
 		), prometheus.CounterValue},
+		paths:        currentPaths(),
 		logger:       logger,
 		nameFilter:   newDeviceFilter(*interruptsExclude, *interruptsInclude),
 		includeZeros: *interruptsIncludeZeros,
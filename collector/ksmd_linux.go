@@ -94,6 +94,7 @@ var (
 )
 
 type ksmdCollector struct {
+	paths  Paths
 	logger *slog.Logger
 }
 
@@ -103,13 +104,16 @@ func init() {
 
 // NewKsmdCollector returns a new Collector exposing kernel/system statistics.
 func NewKsmdCollector(logger *slog.Logger) (Collector, error) {
-	return &ksmdCollector{logger: logger}, nil
+	return &ksmdCollector{
+		paths:  currentPaths(),
+		logger: logger,
+	}, nil
 }
 
 // Update implements Collector and exposes kernel and system statistics.
 func (c *ksmdCollector) Update(ch chan<- prometheus.Metric) error {
 	for _, n := range ksmdFiles {
-		val, err := readUintFromFile(sysFilePath(filepath.Join("kernel/mm/ksm", n)))
+		val, err := readUintFromFile(c.paths.sysFilePath(filepath.Join("kernel/mm/ksm", n)))
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
 				c.logger.Debug("ksmd file not found, skipping", "file", n)
@@ -25,6 +25,7 @@ import (
 )
 
 type lnstatCollector struct {
+	paths  Paths
 	logger *slog.Logger
 }
 
@@ -33,7 +34,10 @@ func init() {
 }
 
 func NewLnstatCollector(logger *slog.Logger) (Collector, error) {
-	return &lnstatCollector{logger}, nil
+	return &lnstatCollector{
+		paths:  currentPaths(),
+		logger: logger,
+	}, nil
 }
 
 func (c *lnstatCollector) Update(ch chan<- prometheus.Metric) error {
@@ -41,7 +45,7 @@ func (c *lnstatCollector) Update(ch chan<- prometheus.Metric) error {
 		subsystem = "lnstat"
 	)
 
-	fs, err := procfs.NewFS(*procPath)
+	fs, err := procfs.NewFS(c.paths.procMountPoint())
 	if err != nil {
 		return fmt.Errorf("failed to open procfs: %w", err)
 	}
@@ -24,6 +24,7 @@ import (
 
 type loadavgCollector struct {
 	metric []typedDesc
+	paths  Paths
 	logger *slog.Logger
 }
 
@@ -39,12 +40,13 @@ func NewLoadavgCollector(logger *slog.Logger) (Collector, error) {
 			{prometheus.NewDesc(namespace+"_load5", "5m load average.", nil, nil), prometheus.GaugeValue},
 			{prometheus.NewDesc(namespace+"_load15", "15m load average.", nil, nil), prometheus.GaugeValue},
 		},
+		paths:  currentPaths(),
 		logger: logger,
 	}, nil
 }
 
 func (c *loadavgCollector) Update(ch chan<- prometheus.Metric) error {
-	loads, err := getLoad()
+	loads, err := getLoad(c.paths)
 	if err != nil {
 		return fmt.Errorf("couldn't get load: %w", err)
 	}
@@ -16,6 +16,7 @@
 package collector
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -23,14 +24,15 @@ import (
 )
 
 // Read loadavg from /proc.
-func getLoad() (loads []float64, err error) {
-	data, err := os.ReadFile(procFilePath("loadavg"))
+func getLoad(paths Paths) (loads []float64, err error) {
+	path := paths.procFilePath("loadavg")
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 	loads, err = parseLoad(string(data))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
 	return loads, nil
 }
@@ -40,7 +42,7 @@ func parseLoad(data string) (loads []float64, err error) {
 	loads = make([]float64, 3)
 	parts := strings.Fields(data)
 	if len(parts) < 3 {
-		return nil, fmt.Errorf("unexpected content in %s", procFilePath("loadavg"))
+		return nil, errors.New("unexpected content")
 	}
 	for i, load := range parts[0:3] {
 		loads[i], err = strconv.ParseFloat(load, 64)
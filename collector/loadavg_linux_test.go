@@ -15,7 +15,16 @@
 
 package collector
 
-import "testing"
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
 
 func TestLoad(t *testing.T) {
 	want := []float64{0.21, 0.37, 0.39}
@@ -30,3 +39,32 @@ func TestLoad(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadTarget(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{"--path.procfs", "/nonexistent"}); err != nil {
+		t.Fatal(err)
+	}
+
+	nc, err := NewTargetCollector(slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Paths{Procfs: "fixtures/proc"},
+		map[string]Config{"loadavg": {}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	if got, want := procFilePath("loadavg"), "/nonexistent/loadavg"; got != want {
+		t.Errorf("Expected global path %s to be restored, Got: %s", want, got)
+	}
+
+	want := `# HELP node_load1 1m load average.
+# TYPE node_load1 gauge
+node_load1 0.21
+`
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(nc)
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "node_load1"); err != nil {
+		t.Fatal(err)
+	}
+}
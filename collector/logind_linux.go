@@ -16,6 +16,7 @@
 package collector
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -91,7 +92,11 @@ func NewLogindCollector(logger *slog.Logger) (Collector, error) {
 }
 
 func (lc *logindCollector) Update(ch chan<- prometheus.Metric) error {
-	c, err := newDbus()
+	return lc.UpdateContext(context.Background(), ch)
+}
+
+func (lc *logindCollector) UpdateContext(ctx context.Context, ch chan<- prometheus.Metric) error {
+	c, err := newDbus(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to connect to dbus: %w", err)
 	}
@@ -145,8 +150,10 @@ func knownStringOrOther(value string, known []string) string {
 	return "other"
 }
 
-func newDbus() (*logindDbus, error) {
-	conn, err := dbus.SystemBusPrivate()
+// newDbus connects to the system bus. The connection is closed once ctx is
+// done, which aborts any pending calls.
+func newDbus(ctx context.Context) (*logindDbus, error) {
+	conn, err := dbus.SystemBusPrivate(dbus.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
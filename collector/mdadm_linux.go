@@ -28,6 +28,7 @@ import (
 )
 
 type mdadmCollector struct {
+	paths  Paths
 	logger *slog.Logger
 }
 
@@ -37,7 +38,10 @@ func init() {
 
 // NewMdadmCollector returns a new Collector exposing raid statistics.
 func NewMdadmCollector(logger *slog.Logger) (Collector, error) {
-	return &mdadmCollector{logger}, nil
+	return &mdadmCollector{
+		paths:  currentPaths(),
+		logger: logger,
+	}, nil
 }
 
 var (
@@ -116,7 +120,7 @@ var (
 )
 
 func (c *mdadmCollector) Update(ch chan<- prometheus.Metric) error {
-	procFS, err := procfs.NewFS(*procPath)
+	procFS, err := procfs.NewFS(c.paths.procMountPoint())
 
 	if err != nil {
 		return fmt.Errorf("failed to open procfs: %w", err)
@@ -125,7 +129,7 @@ func (c *mdadmCollector) Update(ch chan<- prometheus.Metric) error {
 	mdStats, err := procFS.MDStat()
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			c.logger.Debug("Not collecting mdstat, file does not exist", "file", *procPath)
+			c.logger.Debug("Not collecting mdstat, file does not exist", "file", c.paths.procMountPoint())
 			return ErrNoData
 		}
 
@@ -215,14 +219,14 @@ func (c *mdadmCollector) Update(ch chan<- prometheus.Metric) error {
 		)
 	}
 
-	sysFS, err := sysfs.NewFS(*sysPath)
+	sysFS, err := sysfs.NewFS(c.paths.sysMountPoint())
 	if err != nil {
 		return fmt.Errorf("failed to open sysfs: %w", err)
 	}
 	mdraids, err := sysFS.Mdraids()
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			c.logger.Debug("Not collecting mdraids, file does not exist", "file", *sysPath)
+			c.logger.Debug("Not collecting mdraids, file does not exist", "file", c.paths.sysMountPoint())
 			return ErrNoData
 		}
 
@@ -44,6 +44,7 @@ type meminfoMetric struct {
 
 type meminfoNumaCollector struct {
 	metricDescs map[string]*prometheus.Desc
+	paths       Paths
 	logger      *slog.Logger
 }
 
@@ -55,12 +56,13 @@ func init() {
 func NewMeminfoNumaCollector(logger *slog.Logger) (Collector, error) {
 	return &meminfoNumaCollector{
 		metricDescs: map[string]*prometheus.Desc{},
+		paths:       currentPaths(),
 		logger:      logger,
 	}, nil
 }
 
 func (c *meminfoNumaCollector) Update(ch chan<- prometheus.Metric) error {
-	metrics, err := getMemInfoNuma()
+	metrics, err := getMemInfoNuma(c.paths)
 	if err != nil {
 		return fmt.Errorf("couldn't get NUMA meminfo: %w", err)
 	}
@@ -78,12 +80,12 @@ func (c *meminfoNumaCollector) Update(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func getMemInfoNuma() ([]meminfoMetric, error) {
+func getMemInfoNuma(paths Paths) ([]meminfoMetric, error) {
 	var (
 		metrics []meminfoMetric
 	)
 
-	nodes, err := filepath.Glob(sysFilePath("devices/system/node/node[0-9]*"))
+	nodes, err := filepath.Glob(paths.sysFilePath("devices/system/node/node[0-9]*"))
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +41,9 @@ type netClassCollector struct {
 	ignoredDevicesPattern *regexp.Regexp
 	metricDescs           map[string]*prometheus.Desc
 	metricDescsMu         sync.Mutex
+	ignoreInvalidSpeed    bool
+	netlink               bool
+	netlinkWithStats      bool
 	logger                *slog.Logger
 }
 
@@ -60,12 +63,15 @@ func NewNetClassCollector(logger *slog.Logger) (Collector, error) {
 		subsystem:             "network",
 		ignoredDevicesPattern: pattern,
 		metricDescs:           map[string]*prometheus.Desc{},
+		ignoreInvalidSpeed:    *netclassInvalidSpeed,
+		netlink:               *netclassNetlink,
+		netlinkWithStats:      *netclassRTNLWithStats,
 		logger:                logger,
 	}, nil
 }
 
 func (c *netClassCollector) Update(ch chan<- prometheus.Metric) error {
-	if *netclassNetlink {
+	if c.netlink {
 		return c.netClassRTNLUpdate(ch)
 	}
 	return c.netClassSysfsUpdate(ch)
@@ -121,7 +127,7 @@ func (c *netClassCollector) netClassSysfsUpdate(ch chan<- prometheus.Metric) err
 
 		if ifaceInfo.Speed != nil {
 			// Some devices return -1 if the speed is unknown.
-			if *ifaceInfo.Speed >= 0 || !*netclassInvalidSpeed {
+			if *ifaceInfo.Speed >= 0 || !c.ignoreInvalidSpeed {
 				speedBytes := int64(*ifaceInfo.Speed * 1000 * 1000 / 8)
 				pushMetric(ch, c.getFieldDesc("speed_bytes"), speedBytes, prometheus.GaugeValue, ifaceInfo.Name)
 			}
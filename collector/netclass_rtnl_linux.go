@@ -155,7 +155,7 @@ func (c *netClassCollector) netClassRTNLUpdate(ch chan<- prometheus.Metric) erro
 		pushMetric(ch, c.getFieldDesc("protocol_type"), msg.Type, prometheus.GaugeValue, msg.Attributes.Name)
 
 		// Skip statistics if argument collector.netclass_rtnl.with-stats is false or statistics are unavailable.
-		if netclassRTNLWithStats == nil || !*netclassRTNLWithStats || msg.Attributes.Stats64 == nil {
+		if !c.netlinkWithStats || msg.Attributes.Stats64 == nil {
 			continue
 		}
 
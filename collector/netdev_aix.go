@@ -21,7 +21,14 @@ import (
 	"github.com/power-devops/perfstat"
 )
 
-func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
+// netDevConfig holds the platform specific flags, of which there are none.
+type netDevConfig struct{}
+
+func newNetDevConfig() netDevConfig {
+	return netDevConfig{}
+}
+
+func getNetDevStats(_ netDevConfig, _ Paths, filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
 	netDev := netDevStats{}
 
 	stats, err := perfstat.NetAdapterStat()
@@ -51,7 +58,7 @@ func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, err
 	return netDev, nil
 }
 
-func getNetDevLabels() (map[string]map[string]string, error) {
+func getNetDevLabels(_ netDevConfig, _ Paths) (map[string]map[string]string, error) {
 	// to be implemented if needed
 	return nil, nil
 }
@@ -30,7 +30,14 @@ import (
 */
 import "C"
 
-func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
+// netDevConfig holds the platform specific flags, of which there are none.
+type netDevConfig struct{}
+
+func newNetDevConfig() netDevConfig {
+	return netDevConfig{}
+}
+
+func getNetDevStats(_ netDevConfig, _ Paths, filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
 	netDev := netDevStats{}
 
 	var ifap, ifa *C.struct_ifaddrs
@@ -69,6 +76,6 @@ func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, err
 	return netDev, nil
 }
 
-func getNetDevLabels() (map[string]map[string]string, error) {
+func getNetDevLabels(_ netDevConfig, _ Paths) (map[string]map[string]string, error) {
 	return nil, nil
 }
@@ -41,13 +41,17 @@ type netDevCollector struct {
 	deviceFilter     deviceFilter
 	metricDescsMutex sync.Mutex
 	metricDescs      map[string]*prometheus.Desc
+	detailedMetrics  bool
+	addressInfo      bool
+	config           netDevConfig
+	paths            Paths
 	logger           *slog.Logger
 }
 
 type netDevStats map[string]map[string]uint64
 
 func init() {
-	registerCollector("netdev", defaultEnabled, NewNetDevCollector)
+	registerCollector("netdev", defaultEnabled, NewNetDevCollector, "device-include", "device-exclude")
 }
 
 // NewNetDevCollector returns a new Collector exposing network device stats.
@@ -83,10 +87,14 @@ func NewNetDevCollector(logger *slog.Logger) (Collector, error) {
 	}
 
 	return &netDevCollector{
-		subsystem:    "network",
-		deviceFilter: newDeviceFilter(*netdevDeviceExclude, *netdevDeviceInclude),
-		metricDescs:  map[string]*prometheus.Desc{},
-		logger:       logger,
+		subsystem:       "network",
+		deviceFilter:    newDeviceFilter(*netdevDeviceExclude, *netdevDeviceInclude),
+		metricDescs:     map[string]*prometheus.Desc{},
+		detailedMetrics: *netdevDetailedMetrics,
+		addressInfo:     *netdevAddressInfo,
+		config:          newNetDevConfig(),
+		paths:           currentPaths(),
+		logger:          logger,
 	}, nil
 }
 
@@ -107,18 +115,18 @@ func (c *netDevCollector) metricDesc(key string, labels []string) *prometheus.De
 }
 
 func (c *netDevCollector) Update(ch chan<- prometheus.Metric) error {
-	netDev, err := getNetDevStats(&c.deviceFilter, c.logger)
+	netDev, err := getNetDevStats(c.config, c.paths, &c.deviceFilter, c.logger)
 	if err != nil {
 		return fmt.Errorf("couldn't get netstats: %w", err)
 	}
 
-	netDevLabels, err := getNetDevLabels()
+	netDevLabels, err := getNetDevLabels(c.config, c.paths)
 	if err != nil {
 		return fmt.Errorf("couldn't get netdev labels: %w", err)
 	}
 
 	for dev, devStats := range netDev {
-		if !*netdevDetailedMetrics {
+		if !c.detailedMetrics {
 			legacy(devStats)
 		}
 
@@ -136,7 +144,7 @@ func (c *netDevCollector) Update(ch chan<- prometheus.Metric) error {
 			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), labelValues...)
 		}
 	}
-	if *netdevAddressInfo {
+	if c.addressInfo {
 		interfaces, err := net.Interfaces()
 		if err != nil {
 			return fmt.Errorf("could not get network interfaces: %w", err)
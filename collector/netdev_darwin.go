@@ -26,7 +26,14 @@ import (
 	"golang.org/x/sys/unix"
 )
 
-func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
+// netDevConfig holds the platform specific flags, of which there are none.
+type netDevConfig struct{}
+
+func newNetDevConfig() netDevConfig {
+	return netDevConfig{}
+}
+
+func getNetDevStats(_ netDevConfig, _ Paths, filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
 	netDev := netDevStats{}
 
 	ifs, err := net.Interfaces()
@@ -174,7 +181,7 @@ type ifMibData struct {
 	Data          [128]byte // generic information and statistics
 }
 
-func getNetDevLabels() (map[string]map[string]string, error) {
+func getNetDevLabels(_ netDevConfig, _ Paths) (map[string]map[string]string, error) {
 	// to be implemented if needed
 	return nil, nil
 }
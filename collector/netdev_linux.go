@@ -30,11 +30,21 @@ var (
 	netdevLabelIfAlias = kingpin.Flag("collector.netdev.label-ifalias", "Add ifAlias label").Default("false").Bool()
 )
 
-func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
-	if *netDevNetlink {
+// netDevConfig holds the platform specific flags, captured at creation.
+type netDevConfig struct {
+	netlink      bool
+	labelIfAlias bool
+}
+
+func newNetDevConfig() netDevConfig {
+	return netDevConfig{netlink: *netDevNetlink, labelIfAlias: *netdevLabelIfAlias}
+}
+
+func getNetDevStats(config netDevConfig, paths Paths, filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
+	if config.netlink {
 		return netlinkStats(filter, logger)
 	}
-	return procNetDevStats(filter, logger)
+	return procNetDevStats(paths, filter, logger)
 }
 
 func netlinkStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
@@ -141,10 +151,10 @@ func parseNetlinkStats(links []rtnetlink.LinkMessage, filter *deviceFilter, logg
 	return metrics
 }
 
-func procNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
+func procNetDevStats(paths Paths, filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
 	metrics := netDevStats{}
 
-	fs, err := procfs.NewFS(*procPath)
+	fs, err := procfs.NewFS(paths.procMountPoint())
 	if err != nil {
 		return metrics, fmt.Errorf("failed to open procfs: %w", err)
 	}
@@ -185,12 +195,12 @@ func procNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, er
 	return metrics, nil
 }
 
-func getNetDevLabels() (map[string]map[string]string, error) {
-	if !*netdevLabelIfAlias {
+func getNetDevLabels(config netDevConfig, paths Paths) (map[string]map[string]string, error) {
+	if !config.labelIfAlias {
 		return nil, nil
 	}
 
-	fs, err := sysfs.NewFS(*sysPath)
+	fs, err := sysfs.NewFS(paths.sysMountPoint())
 	if err != nil {
 		return nil, err
 	}
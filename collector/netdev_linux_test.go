@@ -18,9 +18,13 @@ package collector
 import (
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/jsimonetti/rtnetlink/v2"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var links = []rtnetlink.LinkMessage{
@@ -338,3 +342,140 @@ func TestNetDevMetricValues(t *testing.T) {
 		}
 	}
 }
+
+func TestNetDevRequestOptions(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	nc, err := NewNodeCollectorWithOptions(logger, Options{
+		"netdev": {"device-include": {"^tun"}},
+	}, "netdev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	c, ok := nc.Collectors["netdev"].(*netDevCollector)
+	if !ok {
+		t.Fatalf("want netdev collector, got %v", nc.Collectors)
+	}
+	if c.deviceFilter.ignored("tun0") || !c.deviceFilter.ignored("eth0") {
+		t.Errorf("want only tun devices accepted, got filter %+v", c.deviceFilter)
+	}
+	if len(nc.owned) != 1 {
+		t.Errorf("want the netdev collector owned by the request, got %d owned collectors", len(nc.owned))
+	}
+	if *netdevDeviceInclude != "" {
+		t.Errorf("want global device-include restored, got %q", *netdevDeviceInclude)
+	}
+
+	for name, options := range map[string]Options{
+		"undeclared option": {"netdev": {"address-info": {"true"}}},
+		"filtered out":      {"cpu": {"device-include": {"^tun"}}},
+		"unknown collector": {"unknown": {"device-include": {"^tun"}}},
+		"invalid regexp":    {"netdev": {"device-include": {"("}}},
+	} {
+		if _, err := NewNodeCollectorWithOptions(logger, options, "netdev"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// netDevProcFixture returns a proc directory whose /proc/net/dev lists a
+// fake0 device only.
+func netDevProcFixture(t *testing.T) string {
+	proc := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(proc, "net"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dev := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+ fake0:    1234       5    0    0    0     0          0         0     5678       6    0    0    0     0       0          0
+`
+	if err := os.WriteFile(filepath.Join(proc, "net", "dev"), []byte(dev), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return proc
+}
+
+// gathersFake0 reports whether c exposes the fake0 device of
+// netDevProcFixture.
+func gathersFake0(t *testing.T, c prometheus.Collector) bool {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != "node_network_receive_bytes_total" {
+			continue
+		}
+		for _, m := range mf.Metric {
+			for _, l := range m.Label {
+				if l.GetName() == "device" && l.GetValue() == "fake0" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func TestNetDevKeepsConfigOptions(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	defer ApplyConfig(nil, nil)
+	defer func(path string) { *procPath = path }(*procPath)
+	*procPath = netDevProcFixture(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	nc, err := NewNodeCollector(logger, "netdev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gathersFake0(t, nc) {
+		t.Fatal("want devices read over netlink by default")
+	}
+
+	err = ApplyConfig(map[string]Config{
+		"netdev": {Options: map[string]any{"netlink": false}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nc, err = NewNodeCollector(logger, "netdev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The collector keeps the options it was created with.
+	if err := ApplyConfig(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !gathersFake0(t, nc) {
+		t.Error("want devices read from /proc/net/dev with netlink disabled by the configuration")
+	}
+}
+
+func TestNetDevTargetNetlink(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	nc, err := NewTargetCollector(logger, Paths{Procfs: netDevProcFixture(t)}, map[string]Config{
+		"netdev": {Options: map[string]any{"netlink": false}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	if !gathersFake0(t, nc) {
+		t.Error("want the devices of the target read with netlink disabled for it")
+	}
+	if !*netDevNetlink {
+		t.Error("want global netlink flag restored")
+	}
+}
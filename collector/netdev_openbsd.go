@@ -28,7 +28,14 @@ import (
 */
 import "C"
 
-func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
+// netDevConfig holds the platform specific flags, of which there are none.
+type netDevConfig struct{}
+
+func newNetDevConfig() netDevConfig {
+	return netDevConfig{}
+}
+
+func getNetDevStats(_ netDevConfig, _ Paths, filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
 	netDev := netDevStats{}
 
 	var ifap, ifa *C.struct_ifaddrs
@@ -70,7 +77,7 @@ func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, err
 	return netDev, nil
 }
 
-func getNetDevLabels() (map[string]map[string]string, error) {
+func getNetDevLabels(_ netDevConfig, _ Paths) (map[string]map[string]string, error) {
 	// to be implemented if needed
 	return nil, nil
 }
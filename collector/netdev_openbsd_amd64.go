@@ -23,7 +23,14 @@ import (
 	"golang.org/x/sys/unix"
 )
 
-func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
+// netDevConfig holds the platform specific flags, of which there are none.
+type netDevConfig struct{}
+
+func newNetDevConfig() netDevConfig {
+	return netDevConfig{}
+}
+
+func getNetDevStats(_ netDevConfig, _ Paths, filter *deviceFilter, logger *slog.Logger) (netDevStats, error) {
 	netDev := netDevStats{}
 
 	mib := [6]_C_int{unix.CTL_NET, unix.AF_ROUTE, 0, 0, unix.NET_RT_IFLIST, 0}
@@ -76,7 +83,7 @@ func getNetDevStats(filter *deviceFilter, logger *slog.Logger) (netDevStats, err
 	return netDev, nil
 }
 
-func getNetDevLabels() (map[string]map[string]string, error) {
+func getNetDevLabels(_ netDevConfig, _ Paths) (map[string]map[string]string, error) {
 	// to be implemented if needed
 	return nil, nil
 }
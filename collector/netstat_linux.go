@@ -41,6 +41,7 @@ var (
 
 type netStatCollector struct {
 	fieldPattern *regexp.Regexp
+	paths        Paths
 	logger       *slog.Logger
 }
 
@@ -54,20 +55,21 @@ func NewNetStatCollector(logger *slog.Logger) (Collector, error) {
 	pattern := regexp.MustCompile(*netStatFields)
 	return &netStatCollector{
 		fieldPattern: pattern,
+		paths:        currentPaths(),
 		logger:       logger,
 	}, nil
 }
 
 func (c *netStatCollector) Update(ch chan<- prometheus.Metric) error {
-	netStats, err := getNetStats(procFilePath("net/netstat"))
+	netStats, err := getNetStats(c.paths.procFilePath("net/netstat"))
 	if err != nil {
 		return fmt.Errorf("couldn't get netstats: %w", err)
 	}
-	snmpStats, err := getNetStats(procFilePath("net/snmp"))
+	snmpStats, err := getNetStats(c.paths.procFilePath("net/snmp"))
 	if err != nil {
 		return fmt.Errorf("couldn't get SNMP stats: %w", err)
 	}
-	snmp6Stats, err := getSNMP6Stats(procFilePath("net/snmp6"))
+	snmp6Stats, err := getSNMP6Stats(c.paths.procFilePath("net/snmp6"))
 	if err != nil {
 		return fmt.Errorf("couldn't get SNMP6 stats: %w", err)
 	}
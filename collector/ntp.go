@@ -16,6 +16,7 @@
 package collector
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net"
@@ -49,6 +50,9 @@ var (
 
 type ntpCollector struct {
 	stratum, leap, rtt, offset, reftime, rootDelay, rootDispersion, sanity typedDesc
+	server                                                                 string
+	version, ttl, port                                                     int
+	offsetTolerance, maxDistance                                           time.Duration
 	logger                                                                 *slog.Logger
 }
 
@@ -120,16 +124,34 @@ func NewNtpCollector(logger *slog.Logger) (Collector, error) {
 			"NTPD sanity according to RFC5905 heuristics and configured limits.",
 			nil, nil,
 		), prometheus.GaugeValue},
-		logger: logger,
+		server:          *ntpServer,
+		version:         *ntpProtocolVersion,
+		ttl:             *ntpIPTTL,
+		port:            *ntpServerPort,
+		offsetTolerance: *ntpOffsetTolerance,
+		maxDistance:     *ntpMaxDistance,
+		logger:          logger,
 	}, nil
 }
 
 func (c *ntpCollector) Update(ch chan<- prometheus.Metric) error {
-	resp, err := ntp.QueryWithOptions(*ntpServer, ntp.QueryOptions{
-		Version: *ntpProtocolVersion,
-		TTL:     *ntpIPTTL,
-		Timeout: time.Second, // default `ntpdate` timeout
-		Port:    *ntpServerPort,
+	return c.UpdateContext(context.Background(), ch)
+}
+
+func (c *ntpCollector) UpdateContext(ctx context.Context, ch chan<- prometheus.Metric) error {
+	timeout := time.Second // default `ntpdate` timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline))
+	}
+	resp, err := ntp.QueryWithOptions(c.server, ntp.QueryOptions{
+		Version: c.version,
+		TTL:     c.ttl,
+		Timeout: timeout,
+		Port:    c.port,
+		Dialer: func(_, remoteAddress string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", remoteAddress)
+		},
 	})
 	if err != nil {
 		return fmt.Errorf("couldn't get SNTP reply: %w", err)
@@ -154,7 +176,7 @@ func (c *ntpCollector) Update(ch chan<- prometheus.Metric) error {
 	// Here is SNTP packet sanity check that is exposed to move burden of
 	// configuration from node_exporter user to the developer.
 
-	maxerr := *ntpOffsetTolerance
+	maxerr := c.offsetTolerance
 	leapMidnightMutex.Lock()
 	if resp.Leap == ntp.LeapAddSecond || resp.Leap == ntp.LeapDelSecond {
 		// state of leapMidnight is cached as leap flag is dropped right after midnight
@@ -166,7 +188,7 @@ func (c *ntpCollector) Update(ch chan<- prometheus.Metric) error {
 	}
 	leapMidnightMutex.Unlock()
 
-	if resp.Validate() == nil && resp.RootDistance <= *ntpMaxDistance && resp.MinError <= maxerr {
+	if resp.Validate() == nil && resp.RootDistance <= c.maxDistance && resp.MinError <= maxerr {
 		ch <- c.sanity.mustNewConstMetric(1)
 	} else {
 		ch <- c.sanity.mustNewConstMetric(0)
@@ -58,6 +58,7 @@ type osRelease struct {
 }
 
 type osReleaseCollector struct {
+	paths              Paths
 	logger             *slog.Logger
 	os                 *osRelease
 	osMutex            sync.RWMutex
@@ -102,6 +103,7 @@ var (
 // NewOSCollector returns a new Collector exposing os-release information.
 func NewOSCollector(logger *slog.Logger) (Collector, error) {
 	return &osReleaseCollector{
+		paths:              currentPaths(),
 		logger:             logger,
 		osReleaseFilenames: []string{etcOSRelease, usrLibOSRelease, systemVersionPlist},
 	}, nil
@@ -173,7 +175,7 @@ func (c *osReleaseCollector) UpdateStruct(path string) error {
 
 func (c *osReleaseCollector) Update(ch chan<- prometheus.Metric) error {
 	for i, path := range c.osReleaseFilenames {
-		err := c.UpdateStruct(*rootfsPath + path)
+		err := c.UpdateStruct(c.paths.rootfsMountPoint() + path)
 		if err == nil {
 			break
 		}
@@ -29,29 +29,119 @@ var (
 	udevDataPath = kingpin.Flag("path.udev.data", "udev data path.").Default("/run/udev/data").String()
 )
 
-func procFilePath(name string) string {
-	return filepath.Join(*procPath, name)
+// Paths are the filesystem locations collectors read from. Collectors
+// capture them at creation, so that collectors reading from different
+// locations, e.g. the root filesystems of several containers, can coexist.
+// Empty fields default to the --path.* flags.
+//
+// Collectors querying the kernel via netlink, ioctls or other syscalls, or
+// systemd via D-Bus, don't read from these paths: they always report on the
+// network namespace and the host the exporter runs in.
+type Paths struct {
+	Procfs   string `yaml:"procfs,omitempty"`
+	Sysfs    string `yaml:"sysfs,omitempty"`
+	Rootfs   string `yaml:"rootfs,omitempty"`
+	UdevData string `yaml:"udev_data,omitempty"`
 }
 
-func sysFilePath(name string) string {
-	return filepath.Join(*sysPath, name)
+// currentPaths returns the paths collectors created now read from.
+func currentPaths() Paths {
+	return Paths{
+		Procfs:   *procPath,
+		Sysfs:    *sysPath,
+		Rootfs:   *rootfsPath,
+		UdevData: *udevDataPath,
+	}
 }
 
-func rootfsFilePath(name string) string {
-	return filepath.Join(*rootfsPath, name)
+// withDefaults returns p with empty fields set from the --path.* flags.
+func (p Paths) withDefaults() Paths {
+	if p.Procfs == "" {
+		p.Procfs = *procPath
+	}
+	if p.Sysfs == "" {
+		p.Sysfs = *sysPath
+	}
+	if p.Rootfs == "" {
+		p.Rootfs = *rootfsPath
+	}
+	if p.UdevData == "" {
+		p.UdevData = *udevDataPath
+	}
+	return p
 }
 
-func udevDataFilePath(name string) string {
-	return filepath.Join(*udevDataPath, name)
+// set makes p the paths collectors created from now on read from and returns
+// a function restoring the previous ones.
+func (p Paths) set() (restore func()) {
+	previous := currentPaths()
+	p = p.withDefaults()
+	*procPath, *sysPath, *rootfsPath, *udevDataPath = p.Procfs, p.Sysfs, p.Rootfs, p.UdevData
+	return func() {
+		*procPath, *sysPath, *rootfsPath, *udevDataPath = previous.Procfs, previous.Sysfs, previous.Rootfs, previous.UdevData
+	}
 }
 
-func rootfsStripPrefix(path string) string {
-	if *rootfsPath == "/" {
+func (p Paths) procMountPoint() string {
+	return p.withDefaults().Procfs
+}
+
+func (p Paths) sysMountPoint() string {
+	return p.withDefaults().Sysfs
+}
+
+func (p Paths) rootfsMountPoint() string {
+	return p.withDefaults().Rootfs
+}
+
+func (p Paths) procFilePath(name string) string {
+	return filepath.Join(p.withDefaults().Procfs, name)
+}
+
+func (p Paths) sysFilePath(name string) string {
+	return filepath.Join(p.withDefaults().Sysfs, name)
+}
+
+func (p Paths) rootfsFilePath(name string) string {
+	return filepath.Join(p.withDefaults().Rootfs, name)
+}
+
+func (p Paths) udevDataFilePath(name string) string {
+	return filepath.Join(p.withDefaults().UdevData, name)
+}
+
+func (p Paths) rootfsStripPrefix(path string) string {
+	rootfs := p.withDefaults().Rootfs
+	if rootfs == "/" {
 		return path
 	}
-	stripped := strings.TrimPrefix(path, *rootfsPath)
+	stripped := strings.TrimPrefix(path, rootfs)
 	if stripped == "" {
 		return "/"
 	}
 	return stripped
 }
+
+// The functions below resolve paths against the --path.* flags. They are meant
+// for use at collector creation; collectors resolve paths at update time
+// against the Paths they captured.
+
+func procFilePath(name string) string {
+	return currentPaths().procFilePath(name)
+}
+
+func sysFilePath(name string) string {
+	return currentPaths().sysFilePath(name)
+}
+
+func rootfsFilePath(name string) string {
+	return currentPaths().rootfsFilePath(name)
+}
+
+func udevDataFilePath(name string) string {
+	return currentPaths().udevDataFilePath(name)
+}
+
+func rootfsStripPrefix(path string) string {
+	return currentPaths().rootfsStripPrefix(path)
+}
@@ -16,6 +16,7 @@
 package collector
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"runtime"
@@ -202,8 +203,11 @@ type perfTracepointCollector struct {
 }
 
 // update is used collect all tracepoints across all tracepoint profilers.
-func (c *perfTracepointCollector) update(ch chan<- prometheus.Metric) error {
+func (c *perfTracepointCollector) update(ctx context.Context, ch chan<- prometheus.Metric) error {
 	for cpu := range c.profilers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := c.updateCPU(cpu, ch); err != nil {
 			return err
 		}
@@ -727,26 +731,34 @@ func NewPerfCollector(logger *slog.Logger) (Collector, error) {
 
 // Update implements the Collector interface and will collect metrics per CPU.
 func (c *perfCollector) Update(ch chan<- prometheus.Metric) error {
-	if err := c.updateHardwareStats(ch); err != nil {
+	return c.UpdateContext(context.Background(), ch)
+}
+
+// UpdateContext is like Update, but stops reading profilers once ctx is done.
+func (c *perfCollector) UpdateContext(ctx context.Context, ch chan<- prometheus.Metric) error {
+	if err := c.updateHardwareStats(ctx, ch); err != nil {
 		return err
 	}
 
-	if err := c.updateSoftwareStats(ch); err != nil {
+	if err := c.updateSoftwareStats(ctx, ch); err != nil {
 		return err
 	}
 
-	if err := c.updateCacheStats(ch); err != nil {
+	if err := c.updateCacheStats(ctx, ch); err != nil {
 		return err
 	}
 	if c.tracepointCollector != nil {
-		return c.tracepointCollector.update(ch)
+		return c.tracepointCollector.update(ctx, ch)
 	}
 
 	return nil
 }
 
-func (c *perfCollector) updateHardwareStats(ch chan<- prometheus.Metric) error {
+func (c *perfCollector) updateHardwareStats(ctx context.Context, ch chan<- prometheus.Metric) error {
 	for _, profiler := range c.perfHwProfilers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		hwProfile := &perf.HardwareProfile{}
 		if err := (*profiler).Profile(hwProfile); err != nil {
 			return err
@@ -830,8 +842,11 @@ func (c *perfCollector) updateHardwareStats(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func (c *perfCollector) updateSoftwareStats(ch chan<- prometheus.Metric) error {
+func (c *perfCollector) updateSoftwareStats(ctx context.Context, ch chan<- prometheus.Metric) error {
 	for _, profiler := range c.perfSwProfilers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		swProfile := &perf.SoftwareProfile{}
 		if err := (*profiler).Profile(swProfile); err != nil {
 			return err
@@ -883,8 +898,11 @@ func (c *perfCollector) updateSoftwareStats(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func (c *perfCollector) updateCacheStats(ch chan<- prometheus.Metric) error {
+func (c *perfCollector) updateCacheStats(ctx context.Context, ch chan<- prometheus.Metric) error {
 	for _, profiler := range c.perfCacheProfilers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		cacheProfile := &perf.CacheProfile{}
 		if err := (*profiler).Profile(cacheProfile); err != nil {
 			return err
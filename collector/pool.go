@@ -0,0 +1,228 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	collectorMaxConcurrency = kingpin.Flag(
+		"collector.max-concurrency",
+		"Maximum number of collectors updating at the same time across all scrapes, the ones with the longest last update going first. Use 0 to disable.",
+	).Default("0").Int()
+
+	workers = &workerPool{}
+
+	// inFlight holds the running updates of the collectors, so that
+	// concurrent scrapes share them. Updates stay in it until the collector
+	// returned, even once abandoned, so that scrapes in the meantime get
+	// their result instead of calling a hung collector again.
+	inFlightMtx sync.Mutex
+	inFlight    = make(map[Collector]*collectorRun)
+)
+
+// workerPool bounds the number of collectors updating at the same time to
+// --collector.max-concurrency. Waiting collectors are admitted by priority.
+type workerPool struct {
+	mtx     sync.Mutex
+	running int
+	waiting []*poolWaiter
+}
+
+type poolWaiter struct {
+	priority time.Duration
+	ready    chan struct{}
+}
+
+// acquire blocks until a worker is free or ctx is done. Waiters with a higher
+// priority are admitted first, the others in turn.
+func (p *workerPool) acquire(ctx context.Context, priority time.Duration) error {
+	p.mtx.Lock()
+	if limit := *collectorMaxConcurrency; limit <= 0 || p.running < limit && len(p.waiting) == 0 {
+		p.running++
+		p.mtx.Unlock()
+		return nil
+	}
+	w := &poolWaiter{priority: priority, ready: make(chan struct{})}
+	p.waiting = append(p.waiting, w)
+	p.mtx.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		p.mtx.Lock()
+		defer p.mtx.Unlock()
+		select {
+		case <-w.ready:
+			// Admitted in the meantime, pass the worker on.
+			p.releaseLocked()
+		default:
+			p.waiting = slices.DeleteFunc(p.waiting, func(o *poolWaiter) bool { return o == w })
+		}
+		return ctx.Err()
+	}
+}
+
+func (p *workerPool) release() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.releaseLocked()
+}
+
+func (p *workerPool) releaseLocked() {
+	p.running--
+	for len(p.waiting) > 0 {
+		if limit := *collectorMaxConcurrency; limit > 0 && p.running >= limit {
+			return
+		}
+		next := 0
+		for i, w := range p.waiting {
+			if w.priority > p.waiting[next].priority {
+				next = i
+			}
+		}
+		close(p.waiting[next].ready)
+		p.waiting = slices.Delete(p.waiting, next, next+1)
+		p.running++
+	}
+}
+
+// collectorRun is an update of a collector, shared by all scrapes asking for
+// one while it runs.
+type collectorRun struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	// waiters is the number of scrapes waiting for the update, guarded by
+	// inFlightMtx. The update is cancelled once all of them gave up.
+	waiters int
+
+	// The result, set once done is closed.
+	metrics  []prometheus.Metric
+	err      error
+	timedOut bool
+	begin    time.Time
+	duration time.Duration
+}
+
+// joinRun starts an update of c, or joins the one already running, and
+// waits for it to finish or for ctx to be done, in which case ctx's error is
+// returned. The update isn't bound to ctx but to the collector's timeout and
+// is cancelled once no scrape waits for it anymore. An update which timed
+// out or was cancelled while the collector hasn't returned yet is finished
+// already, so that joining it fails right away. Collectors which can't be
+// told apart, as they aren't comparable, are never shared.
+func joinRun(ctx context.Context, name string, c Collector, timeout time.Duration) (*collectorRun, error) {
+	shared := reflect.TypeOf(c).Comparable()
+
+	inFlightMtx.Lock()
+	r, ok := inFlight[c]
+	if !shared || !ok {
+		r = startRun(ctx, name, c, timeout)
+		if shared {
+			inFlight[c] = r
+		}
+	}
+	r.waiters++
+	inFlightMtx.Unlock()
+
+	select {
+	case <-r.done:
+		return r, nil
+	case <-ctx.Done():
+		inFlightMtx.Lock()
+		defer inFlightMtx.Unlock()
+		r.waiters--
+		if r.waiters == 0 {
+			r.cancel()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func startRun(ctx context.Context, name string, c Collector, timeout time.Duration) *collectorRun {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	r := &collectorRun{done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer cancel()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		r.err = r.update(ctx, name, c, func() {
+			inFlightMtx.Lock()
+			defer inFlightMtx.Unlock()
+			if inFlight[c] == r {
+				delete(inFlight, c)
+			}
+		})
+		r.duration = time.Since(r.begin)
+		r.timedOut = ctx.Err() != nil && errors.Is(r.err, context.DeadlineExceeded)
+		close(r.done)
+	}()
+	return r
+}
+
+// update runs the collector on a worker of the pool and buffers its metrics.
+// The worker is only released once the collector returned, so that
+// abandoned collectors still count against the limit. Collectors running in
+// the background only serve their last result and don't need a worker.
+// finished is called once the collector returned, or right away if it was
+// never called.
+func (r *collectorRun) update(ctx context.Context, name string, c Collector, finished func()) error {
+	r.begin = time.Now()
+	if _, ok := c.(*backgroundCollector); !ok {
+		if err := workers.acquire(ctx, lastDuration(name)); err != nil {
+			finished()
+			return err
+		}
+		forget := finished
+		finished = func() {
+			workers.release()
+			forget()
+		}
+		r.begin = time.Now()
+	}
+
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		for m := range ch {
+			r.metrics = append(r.metrics, m)
+		}
+		close(done)
+	}()
+	err := update(ctx, c, ch, finished)
+	close(ch)
+	<-done
+	return err
+}
+
+// lastDuration returns the duration of the last recorded update of the named
+// collector.
+func lastDuration(name string) time.Duration {
+	runsMtx.Lock()
+	defer runsMtx.Unlock()
+	return time.Duration(lastRuns[name].DurationSeconds * float64(time.Second))
+}
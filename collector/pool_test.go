@@ -0,0 +1,276 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// waitFor polls cond until it holds or a second passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWorkerPoolPriority(t *testing.T) {
+	limit := *collectorMaxConcurrency
+	defer func() { *collectorMaxConcurrency = limit }()
+	*collectorMaxConcurrency = 1
+
+	p := &workerPool{}
+	if err := p.acquire(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mtx   sync.Mutex
+		order []time.Duration
+		wg    sync.WaitGroup
+	)
+	for i, priority := range []time.Duration{time.Second, 3 * time.Second, 2 * time.Second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.acquire(context.Background(), priority); err != nil {
+				t.Error(err)
+				return
+			}
+			mtx.Lock()
+			order = append(order, priority)
+			mtx.Unlock()
+			p.release()
+		}()
+		waitFor(t, func() bool {
+			p.mtx.Lock()
+			defer p.mtx.Unlock()
+			return len(p.waiting) == i+1
+		})
+	}
+
+	// A waiter giving up leaves the queue.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.acquire(ctx, time.Hour); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want deadline exceeded, got %v", err)
+	}
+
+	p.release()
+	wg.Wait()
+	if want := []time.Duration{3 * time.Second, 2 * time.Second, time.Second}; !slices.Equal(order, want) {
+		t.Errorf("want admission in order %v, got %v", want, order)
+	}
+	if p.running != 0 || len(p.waiting) != 0 {
+		t.Errorf("want idle pool, got %d running and %d waiting", p.running, len(p.waiting))
+	}
+}
+
+type blockingCollector struct {
+	name    string
+	release chan struct{}
+	updates atomic.Int32
+	running atomic.Int32
+}
+
+func (c *blockingCollector) Update(ch chan<- prometheus.Metric) error {
+	c.updates.Add(1)
+	c.running.Add(1)
+	defer c.running.Add(-1)
+	<-c.release
+	ch <- prometheus.MustNewConstMetric(testSeriesDesc, prometheus.GaugeValue, 1, c.name, "0")
+	return nil
+}
+
+func TestNodeCollectorSharesRuns(t *testing.T) {
+	c := &blockingCollector{name: "blocking", release: make(chan struct{})}
+	nc := &NodeCollector{
+		Collectors: map[string]Collector{"blocking": c},
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	var wg sync.WaitGroup
+	counts := make([]int, 3)
+	for i := range counts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(nc)
+			n, err := testutil.GatherAndCount(reg, "node_test_series")
+			if err != nil {
+				t.Error(err)
+			}
+			counts[i] = n
+		}()
+	}
+	waitFor(t, func() bool {
+		inFlightMtx.Lock()
+		defer inFlightMtx.Unlock()
+		r, ok := inFlight[Collector(c)]
+		return ok && r.waiters == len(counts)
+	})
+	close(c.release)
+	wg.Wait()
+
+	if n := c.updates.Load(); n != 1 {
+		t.Errorf("want a single update shared by all scrapes, got %d", n)
+	}
+	if !slices.Equal(counts, []int{1, 1, 1}) {
+		t.Errorf("want the metric in every scrape, got %v", counts)
+	}
+}
+
+func TestNodeCollectorMaxConcurrency(t *testing.T) {
+	limit := *collectorMaxConcurrency
+	defer func() { *collectorMaxConcurrency = limit }()
+	*collectorMaxConcurrency = 2
+
+	release := make(chan struct{})
+	collectors := map[string]Collector{}
+	var all []*blockingCollector
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		c := &blockingCollector{name: name, release: release}
+		collectors[name] = c
+		all = append(all, c)
+	}
+	nc := &NodeCollector{
+		Collectors: collectors,
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(nc)
+		if n, err := testutil.GatherAndCount(reg, "node_test_series"); err != nil || n != 5 {
+			t.Errorf("want 5 series, got %d (err %v)", n, err)
+		}
+	}()
+	running := func() int {
+		var n int32
+		for _, c := range all {
+			n += c.running.Load()
+		}
+		return int(n)
+	}
+	waitFor(t, func() bool { return running() == 2 })
+	time.Sleep(10 * time.Millisecond)
+	if n := running(); n != 2 {
+		t.Errorf("want 2 collectors running, got %d", n)
+	}
+	close(release)
+	<-done
+}
+
+func TestAbandonedCollectorHoldsWorker(t *testing.T) {
+	limit := *collectorMaxConcurrency
+	defer func() { *collectorMaxConcurrency = limit }()
+	*collectorMaxConcurrency = 1
+
+	c := &blockingCollector{name: "hung", release: make(chan struct{})}
+	r := startRun(context.Background(), "hung", c, 10*time.Millisecond)
+	<-r.done
+	if !r.timedOut {
+		t.Fatalf("want timed out run, got %v", r.err)
+	}
+
+	running := func() int {
+		workers.mtx.Lock()
+		defer workers.mtx.Unlock()
+		return workers.running
+	}
+	if n := running(); n != 1 {
+		t.Errorf("want the worker held by the abandoned collector, got %d running", n)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := workers.acquire(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want no worker free, got %v", err)
+	}
+
+	close(c.release)
+	waitFor(t, func() bool { return running() == 0 })
+}
+
+func TestHungCollectorNotUpdatedAgain(t *testing.T) {
+	limit, overrides := *collectorMaxConcurrency, *collectorTimeoutOverrides
+	defer func() { *collectorMaxConcurrency, *collectorTimeoutOverrides = limit, overrides }()
+	*collectorMaxConcurrency = 2
+	*collectorTimeoutOverrides = collectorDurations{"hung": 20 * time.Millisecond}
+
+	hung := &blockingCollector{name: "hung", release: make(chan struct{})}
+	nc := &NodeCollector{
+		Collectors: map[string]Collector{
+			"fast": testCollector{},
+			"hung": hung,
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	want := `# HELP node_scrape_collector_success node_exporter: Whether a collector succeeded.
+# TYPE node_scrape_collector_success gauge
+node_scrape_collector_success{collector="fast"} 1
+node_scrape_collector_success{collector="hung"} 0
+# HELP node_scrape_collector_timeout node_exporter: Whether a collector was abandoned because it exceeded its timeout.
+# TYPE node_scrape_collector_timeout gauge
+node_scrape_collector_timeout{collector="fast"} 0
+node_scrape_collector_timeout{collector="hung"} 1
+`
+	// Scrapes while the collector hangs get the result of its abandoned
+	// update, leaving the other worker to the healthy collectors.
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(nc.WithContext(ctx))
+		err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+			"node_scrape_collector_success", "node_scrape_collector_timeout")
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := hung.updates.Load(); n != 1 {
+		t.Errorf("want the hung collector updated once, got %d updates", n)
+	}
+
+	close(hung.release)
+	waitFor(t, func() bool {
+		inFlightMtx.Lock()
+		defer inFlightMtx.Unlock()
+		_, ok := inFlight[Collector(hung)]
+		return !ok
+	})
+	workers.mtx.Lock()
+	defer workers.mtx.Unlock()
+	if workers.running != 0 {
+		t.Errorf("want all workers released, got %d running", workers.running)
+	}
+}
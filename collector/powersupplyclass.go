@@ -31,6 +31,7 @@ type powerSupplyClassCollector struct {
 	subsystem      string
 	ignoredPattern *regexp.Regexp
 	metricDescs    map[string]*prometheus.Desc
+	paths          Paths
 	logger         *slog.Logger
 }
 
@@ -44,6 +45,7 @@ func NewPowerSupplyClassCollector(logger *slog.Logger) (Collector, error) {
 		subsystem:      "power_supply",
 		ignoredPattern: pattern,
 		metricDescs:    map[string]*prometheus.Desc{},
+		paths:          currentPaths(),
 		logger:         logger,
 	}, nil
 }
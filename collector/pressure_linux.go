@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"syscall"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs"
 )
@@ -34,24 +36,72 @@ const (
 )
 
 var (
-	psiResources = []string{psiResourceCPU, psiResourceIO, psiResourceMemory, psiResourceIRQ}
+	pressureCgroupDepth   = kingpin.Flag("collector.pressure.cgroup-depth", "Depth of the cgroup hierarchy to walk for the cgroups to expose the pressure of, the root cgroup being at depth 0.").Default("2").Int()
+	pressureCgroupInclude = kingpin.Flag("collector.pressure.cgroup-include", "Regexp of cgroup v2 paths to expose the pressure of, e.g. /system.slice/.+\\.service. No cgroup is read if unset.").String()
+	pressureCgroupExclude = kingpin.Flag("collector.pressure.cgroup-exclude", "Regexp of cgroup v2 paths not to expose the pressure of.").String()
+
+	psiResources       = []string{psiResourceCPU, psiResourceIO, psiResourceMemory, psiResourceIRQ}
+	psiCgroupResources = []string{psiResourceCPU, psiResourceIO, psiResourceMemory}
+
+	// pressureHelp describes the some and the full line of the resources,
+	// the lines not exposed being empty.
+	pressureHelp = map[string][2]string{
+		psiResourceCPU:    {"that processes have waited for CPU time", ""},
+		psiResourceIO:     {"that processes have waited due to IO congestion", "no process could make progress due to IO congestion"},
+		psiResourceMemory: {"that processes have waited for memory", "no process could make progress due to memory congestion"},
+		psiResourceIRQ:    {"", "no process could make progress due to IRQ congestion"},
+	}
+
+	// pressureWindows are the windows of the avg10, avg60 and avg300
+	// averages.
+	pressureWindows = []string{"10s", "60s", "300s"}
 )
 
+// pressureMetrics are the metrics of the pressure of a resource, the total
+// time and its averages, for the some and the full line. Lines without
+// metrics aren't exposed.
+type pressureMetrics struct {
+	some, someRatio *prometheus.Desc
+	full, fullRatio *prometheus.Desc
+}
+
+func newPressureMetrics(prefix, res string, labels ...string) pressureMetrics {
+	desc := func(name, help string, labels []string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pressure", prefix+res+name), help, labels, nil)
+	}
+	windowLabels := append(labels[:len(labels):len(labels)], "window")
+
+	var m pressureMetrics
+	help := pressureHelp[res]
+	if help[0] != "" {
+		m.some = desc("_waiting_seconds_total", "Total time in seconds "+help[0], labels)
+		m.someRatio = desc("_waiting_ratio", "Share of time "+help[0]+", averaged over the window", windowLabels)
+	}
+	if help[1] != "" {
+		m.full = desc("_stalled_seconds_total", "Total time in seconds "+help[1], labels)
+		m.fullRatio = desc("_stalled_ratio", "Share of time "+help[1]+", averaged over the window", windowLabels)
+	}
+	return m
+}
+
 type pressureStatsCollector struct {
-	cpu     *prometheus.Desc
-	io      *prometheus.Desc
-	ioFull  *prometheus.Desc
-	mem     *prometheus.Desc
-	memFull *prometheus.Desc
-	irqFull *prometheus.Desc
+	metrics       map[string]pressureMetrics
+	cgroupMetrics map[string]pressureMetrics
+
+	// cgroups tells whether the pressure of the cgroups passing
+	// cgroupFilter is exposed.
+	cgroups      bool
+	cgroupDepth  int
+	cgroupFilter deviceFilter
 
-	fs procfs.FS
+	fs    procfs.FS
+	paths Paths
 
 	logger *slog.Logger
 }
 
 func init() {
-	registerCollector("pressure", defaultEnabled, NewPressureStatsCollector)
+	registerCollector("pressure", defaultEnabled, NewPressureStatsCollector, "cgroup-include", "cgroup-exclude", "cgroup-depth")
 }
 
 // NewPressureStatsCollector returns a Collector exposing pressure stall information
@@ -61,40 +111,23 @@ func NewPressureStatsCollector(logger *slog.Logger) (Collector, error) {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
 
-	return &pressureStatsCollector{
-		cpu: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "pressure", "cpu_waiting_seconds_total"),
-			"Total time in seconds that processes have waited for CPU time",
-			nil, nil,
-		),
-		io: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "pressure", "io_waiting_seconds_total"),
-			"Total time in seconds that processes have waited due to IO congestion",
-			nil, nil,
-		),
-		ioFull: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "pressure", "io_stalled_seconds_total"),
-			"Total time in seconds no process could make progress due to IO congestion",
-			nil, nil,
-		),
-		mem: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "pressure", "memory_waiting_seconds_total"),
-			"Total time in seconds that processes have waited for memory",
-			nil, nil,
-		),
-		memFull: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "pressure", "memory_stalled_seconds_total"),
-			"Total time in seconds no process could make progress due to memory congestion",
-			nil, nil,
-		),
-		irqFull: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "pressure", "irq_stalled_seconds_total"),
-			"Total time in seconds no process could make progress due to IRQ congestion",
-			nil, nil,
-		),
-		fs:     fs,
-		logger: logger,
-	}, nil
+	c := &pressureStatsCollector{
+		metrics:       map[string]pressureMetrics{},
+		cgroupMetrics: map[string]pressureMetrics{},
+		cgroups:       *pressureCgroupInclude != "",
+		cgroupDepth:   *pressureCgroupDepth,
+		cgroupFilter:  newDeviceFilter(*pressureCgroupExclude, *pressureCgroupInclude),
+		fs:            fs,
+		paths:         currentPaths(),
+		logger:        logger,
+	}
+	for _, res := range psiResources {
+		c.metrics[res] = newPressureMetrics("", res)
+	}
+	for _, res := range psiCgroupResources {
+		c.cgroupMetrics[res] = newPressureMetrics("cgroup_", res, "cgroup")
+	}
+	return c, nil
 }
 
 // Update calls procfs.NewPSIStatsForResource for the different resources and updates the values
@@ -128,21 +161,7 @@ func (c *pressureStatsCollector) Update(ch chan<- prometheus.Metric) error {
 			c.logger.Debug("pressure information returned no 'full' data")
 			return ErrNoData
 		}
-		switch res {
-		case psiResourceCPU:
-			ch <- prometheus.MustNewConstMetric(c.cpu, prometheus.CounterValue, float64(vals.Some.Total)/1000.0/1000.0)
-		case psiResourceIO:
-			ch <- prometheus.MustNewConstMetric(c.io, prometheus.CounterValue, float64(vals.Some.Total)/1000.0/1000.0)
-			ch <- prometheus.MustNewConstMetric(c.ioFull, prometheus.CounterValue, float64(vals.Full.Total)/1000.0/1000.0)
-		case psiResourceMemory:
-			ch <- prometheus.MustNewConstMetric(c.mem, prometheus.CounterValue, float64(vals.Some.Total)/1000.0/1000.0)
-			ch <- prometheus.MustNewConstMetric(c.memFull, prometheus.CounterValue, float64(vals.Full.Total)/1000.0/1000.0)
-		case psiResourceIRQ:
-			ch <- prometheus.MustNewConstMetric(c.irqFull, prometheus.CounterValue, float64(vals.Full.Total)/1000.0/1000.0)
-		default:
-			c.logger.Debug("did not account for resource", "resource", res)
-			continue
-		}
+		updatePressure(ch, c.metrics[res], vals)
 		foundResources++
 	}
 
@@ -151,5 +170,52 @@ func (c *pressureStatsCollector) Update(ch chan<- prometheus.Metric) error {
 		return ErrNoData
 	}
 
+	if c.cgroups {
+		return c.updateCgroups(ch)
+	}
 	return nil
 }
+
+// updateCgroups exposes the pressure of the selected cgroups of the unified
+// hierarchy. Cgroups without pressure files, as in hybrid setups, or with
+// them disabled through cgroup.pressure are skipped.
+func (c *pressureStatsCollector) updateCgroups(ch chan<- prometheus.Metric) error {
+	root, err := cgroupv2Root(c.paths)
+	if err != nil {
+		c.logger.Debug("cgroup v2 hierarchy not found, no cgroup pressure exposed", "path", c.paths.sysFilePath("fs/cgroup"))
+		return nil
+	}
+	return walkCgroups(root, c.cgroupDepth, c.cgroupFilter, func(dir, cgroup string) error {
+		for _, res := range psiCgroupResources {
+			vals, err := readPSIStats(filepath.Join(dir, res+".pressure"))
+			if err != nil {
+				if err := cgroupFileErr(err); err != nil {
+					return err
+				}
+				continue
+			}
+			updatePressure(ch, c.cgroupMetrics[res], vals, cgroup)
+		}
+		return nil
+	})
+}
+
+// updatePressure exposes the total time in seconds and the averages, given
+// in percent, of the lines of vals which m has metrics for.
+func updatePressure(ch chan<- prometheus.Metric, m pressureMetrics, vals procfs.PSIStats, labels ...string) {
+	for _, l := range []struct {
+		total, ratio *prometheus.Desc
+		line         *procfs.PSILine
+	}{
+		{m.some, m.someRatio, vals.Some},
+		{m.full, m.fullRatio, vals.Full},
+	} {
+		if l.total == nil || l.line == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(l.total, prometheus.CounterValue, float64(l.line.Total)/1000.0/1000.0, labels...)
+		for i, avg := range []float64{l.line.Avg10, l.line.Avg60, l.line.Avg300} {
+			ch <- prometheus.MustNewConstMetric(l.ratio, prometheus.GaugeValue, avg/100, append(labels[:len(labels):len(labels)], pressureWindows[i])...)
+		}
+	}
+}
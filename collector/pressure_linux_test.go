@@ -0,0 +1,105 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nopressure
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type testPressureCollector struct {
+	c *pressureStatsCollector
+}
+
+func (c testPressureCollector) Collect(ch chan<- prometheus.Metric) {
+	if err := c.c.updateCgroups(ch); err != nil {
+		panic(err)
+	}
+}
+
+func (c testPressureCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func TestPressureCgroups(t *testing.T) {
+	c := &pressureStatsCollector{
+		cgroupMetrics: map[string]pressureMetrics{},
+		cgroups:       true,
+		cgroupDepth:   2,
+		cgroupFilter:  newDeviceFilter(`/system\.slice/.+`, `/.+`),
+		paths:         Paths{Sysfs: "fixtures/sys"},
+		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	for _, res := range psiCgroupResources {
+		c.cgroupMetrics[res] = newPressureMetrics("cgroup_", res, "cgroup")
+	}
+
+	want := `# HELP node_pressure_cgroup_cpu_waiting_ratio Share of time that processes have waited for CPU time, averaged over the window
+# TYPE node_pressure_cgroup_cpu_waiting_ratio gauge
+node_pressure_cgroup_cpu_waiting_ratio{cgroup="/init.scope",window="10s"} 0
+node_pressure_cgroup_cpu_waiting_ratio{cgroup="/init.scope",window="300s"} 0
+node_pressure_cgroup_cpu_waiting_ratio{cgroup="/init.scope",window="60s"} 0
+node_pressure_cgroup_cpu_waiting_ratio{cgroup="/system.slice",window="10s"} 0.0121
+node_pressure_cgroup_cpu_waiting_ratio{cgroup="/system.slice",window="300s"} 0
+node_pressure_cgroup_cpu_waiting_ratio{cgroup="/system.slice",window="60s"} 0
+# HELP node_pressure_cgroup_io_stalled_seconds_total Total time in seconds no process could make progress due to IO congestion
+# TYPE node_pressure_cgroup_io_stalled_seconds_total counter
+node_pressure_cgroup_io_stalled_seconds_total{cgroup="/init.scope"} 0
+node_pressure_cgroup_io_stalled_seconds_total{cgroup="/system.slice"} 1.001233
+`
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(testPressureCollector{c})
+	err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"node_pressure_cgroup_cpu_waiting_ratio", "node_pressure_cgroup_io_stalled_seconds_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The cgroups below the depth aren't walked.
+	c.cgroupDepth = 0
+	if n, err := testutil.GatherAndCount(reg, "node_pressure_cgroup_cpu_waiting_ratio"); err != nil || n != 0 {
+		t.Errorf("want no cgroup below depth 0, got %d series (err %v)", n, err)
+	}
+}
+
+func TestPressureRequestOptions(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	nc, err := NewNodeCollectorWithOptions(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{
+		"pressure": {"cgroup-include": {"/system.slice/.+"}, "cgroup-depth": {"1"}},
+	}, "pressure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	c, ok := nc.Collectors["pressure"].(*pressureStatsCollector)
+	if !ok {
+		t.Fatalf("want pressure collector, got %v", nc.Collectors)
+	}
+	if !c.cgroups || c.cgroupDepth != 1 {
+		t.Errorf("want cgroups walked down to depth 1, got cgroups %t and depth %d", c.cgroups, c.cgroupDepth)
+	}
+	if *pressureCgroupDepth != 2 {
+		t.Errorf("want global cgroup-depth restored, got %d", *pressureCgroupDepth)
+	}
+}
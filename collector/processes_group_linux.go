@@ -0,0 +1,328 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noprocesses
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+// processUserHZ is the unit of the CPU times in /proc/<pid>/stat, fixed to
+// 100 on Linux.
+const processUserHZ = 100
+
+var (
+	processGroupFlags = kingpin.Flag("collector.processes.group", "Group the processes matching a rule and expose their resource usage, as <group>=<matcher>:<regexp> with matcher one of comm, exe, cgroup or user. The first matching rule wins. (repeatable)").PlaceHolder("<group>=<matcher>:<regexp>").Strings()
+
+	processGroupMatchers = []string{"comm", "exe", "cgroup", "user"}
+)
+
+// processGroupRule puts the processes whose matcher value, e.g. their comm,
+// matches regexp in group.
+type processGroupRule struct {
+	group   string
+	matcher string
+	regexp  *regexp.Regexp
+}
+
+func parseProcessGroupRules(flags []string) ([]processGroupRule, error) {
+	var rules []processGroupRule
+	for _, flag := range flags {
+		group, rule, ok := strings.Cut(flag, "=")
+		if !ok || group == "" {
+			return nil, fmt.Errorf("invalid process group %q, expected <group>=<matcher>:<regexp>", flag)
+		}
+		matcher, expr, ok := strings.Cut(rule, ":")
+		if !ok || !slices.Contains(processGroupMatchers, matcher) {
+			return nil, fmt.Errorf("invalid process group %q, expected a matcher among %s", flag, strings.Join(processGroupMatchers, ", "))
+		}
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid process group %q: %w", flag, err)
+		}
+		rules = append(rules, processGroupRule{group: group, matcher: matcher, regexp: re})
+	}
+	return rules, nil
+}
+
+type processGroupDescs struct {
+	processes    *prometheus.Desc
+	threads      *prometheus.Desc
+	cpu          *prometheus.Desc
+	rss          *prometheus.Desc
+	pss          *prometheus.Desc
+	fds          *prometheus.Desc
+	readBytes    *prometheus.Desc
+	writtenBytes *prometheus.Desc
+	ctxSwitches  *prometheus.Desc
+}
+
+func newProcessGroupDescs(subsystem string) processGroupDescs {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "group_"+name),
+			help, append([]string{"group"}, labels...), nil,
+		)
+	}
+	return processGroupDescs{
+		processes:    desc("processes", "Number of processes in the group."),
+		threads:      desc("threads", "Number of threads of the processes in the group."),
+		cpu:          desc("cpu_seconds_total", "CPU time spent by the processes in the group, by mode.", "mode"),
+		rss:          desc("resident_memory_bytes", "Resident memory of the processes in the group."),
+		pss:          desc("proportional_memory_bytes", "Proportional set size of the processes in the group, shared pages being divided among their users."),
+		fds:          desc("open_fds", "Number of file descriptors opened by the processes in the group."),
+		readBytes:    desc("read_bytes_total", "Number of bytes read from storage by the processes in the group."),
+		writtenBytes: desc("written_bytes_total", "Number of bytes written to storage by the processes in the group."),
+		ctxSwitches:  desc("context_switches_total", "Number of context switches of the processes in the group, by type.", "type"),
+	}
+}
+
+// processGroupStats sums the resource usage of the processes in a group.
+type processGroupStats struct {
+	processGroupCounters
+	processes int
+	threads   int
+	rss, pss  uint64
+	fds       int
+}
+
+// processGroupCounters are the counters of a process, or the sums of the
+// ones of the processes of a group.
+type processGroupCounters struct {
+	userSeconds, systemSeconds             float64
+	readBytes, writtenBytes                uint64
+	voluntarySwitches, involuntarySwitches uint64
+}
+
+func (c *processGroupCounters) add(o processGroupCounters) {
+	c.userSeconds += o.userSeconds
+	c.systemSeconds += o.systemSeconds
+	c.readBytes += o.readBytes
+	c.writtenBytes += o.writtenBytes
+	c.voluntarySwitches += o.voluntarySwitches
+	c.involuntarySwitches += o.involuntarySwitches
+}
+
+// processGroupMember is a process grouped at the last scrape, identified by
+// its PID and start time as PIDs are reused.
+type processGroupMember struct {
+	pid       int
+	starttime uint64
+}
+
+// processGroupMemberStats are the counters of a member as of the last scrape.
+type processGroupMemberStats struct {
+	group    string
+	counters processGroupCounters
+}
+
+// groupedProcess is a process being grouped, its status being read once at
+// most.
+type groupedProcess struct {
+	procfs.Proc
+	stat   procfs.ProcStat
+	status *procfs.ProcStatus
+}
+
+func (p *groupedProcess) Status() (procfs.ProcStatus, error) {
+	if p.status == nil {
+		status, err := p.NewStatus()
+		if err != nil {
+			return status, err
+		}
+		p.status = &status
+	}
+	return *p.status, nil
+}
+
+func (c *processCollector) updateGroups(ch chan<- prometheus.Metric) error {
+	procs, err := c.fs.AllProcs()
+	if err != nil {
+		return fmt.Errorf("unable to list all processes: %w", err)
+	}
+
+	c.groupMtx.Lock()
+	defer c.groupMtx.Unlock()
+	if c.groupExited == nil {
+		c.groupExited = map[string]*processGroupCounters{}
+	}
+
+	groups := map[string]*processGroupStats{}
+	var users map[uint64]string
+	for _, rule := range c.groupRules {
+		groups[rule.group] = &processGroupStats{}
+		if c.groupExited[rule.group] == nil {
+			c.groupExited[rule.group] = &processGroupCounters{}
+		}
+		if rule.matcher == "user" && users == nil {
+			users = c.readUsers()
+		}
+	}
+
+	members := map[processGroupMember]processGroupMemberStats{}
+	for _, proc := range procs {
+		stat, err := proc.Stat()
+		if err != nil {
+			// PIDs can vanish between getting the list and getting stats.
+			if c.isIgnoredError(err) {
+				continue
+			}
+			return fmt.Errorf("error reading stat for pid %d: %w", proc.PID, err)
+		}
+		p := &groupedProcess{Proc: proc, stat: stat}
+		group, ok := c.processGroup(p, users)
+		if !ok {
+			continue
+		}
+		member := processGroupMember{pid: proc.PID, starttime: stat.Starttime}
+		var last processGroupCounters
+		if m, ok := c.groupMembers[member]; ok && m.group == group {
+			last = m.counters
+		}
+		members[member] = processGroupMemberStats{group: group, counters: c.addProcess(groups[group], p, last)}
+	}
+
+	// The counters of the processes which left their group, mostly by
+	// exiting, are kept as of the last scrape so that the ones of the group
+	// don't decrease.
+	for member, m := range c.groupMembers {
+		if cur, ok := members[member]; !ok || cur.group != m.group {
+			c.groupExited[m.group].add(m.counters)
+		}
+	}
+	c.groupMembers = members
+
+	for group, s := range groups {
+		s.add(*c.groupExited[group])
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.processes, prometheus.GaugeValue, float64(s.processes), group)
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.threads, prometheus.GaugeValue, float64(s.threads), group)
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.cpu, prometheus.CounterValue, s.userSeconds, group, "user")
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.cpu, prometheus.CounterValue, s.systemSeconds, group, "system")
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.rss, prometheus.GaugeValue, float64(s.rss), group)
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.pss, prometheus.GaugeValue, float64(s.pss), group)
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.fds, prometheus.GaugeValue, float64(s.fds), group)
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.readBytes, prometheus.CounterValue, float64(s.readBytes), group)
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.writtenBytes, prometheus.CounterValue, float64(s.writtenBytes), group)
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.ctxSwitches, prometheus.CounterValue, float64(s.voluntarySwitches), group, "voluntary")
+		ch <- prometheus.MustNewConstMetric(c.groupDescs.ctxSwitches, prometheus.CounterValue, float64(s.involuntarySwitches), group, "involuntary")
+	}
+	return nil
+}
+
+// processGroup returns the group of the first rule matching p. Processes
+// whose matcher value can't be read, e.g. the exe of a process of another
+// user, don't match.
+func (c *processCollector) processGroup(p *groupedProcess, users map[uint64]string) (string, bool) {
+	for _, rule := range c.groupRules {
+		var values []string
+		switch rule.matcher {
+		case "comm":
+			values = []string{p.stat.Comm}
+		case "exe":
+			if exe, err := p.Executable(); err == nil {
+				values = []string{exe}
+			}
+		case "cgroup":
+			cgroups, err := p.Cgroups()
+			if err == nil {
+				for _, cgroup := range cgroups {
+					values = append(values, cgroup.Path)
+				}
+			}
+		case "user":
+			if status, err := p.Status(); err == nil {
+				uid := status.UIDs[0]
+				values = []string{strconv.FormatUint(uid, 10)}
+				if name, ok := users[uid]; ok {
+					values = append(values, name)
+				}
+			}
+		}
+		if slices.ContainsFunc(values, rule.regexp.MatchString) {
+			return rule.group, true
+		}
+	}
+	return "", false
+}
+
+// addProcess adds the resource usage of p to s and returns its counters. The
+// files only readable by privileged users are skipped if they can't be read,
+// the counters read from them keeping their last values.
+func (c *processCollector) addProcess(s *processGroupStats, p *groupedProcess, last processGroupCounters) processGroupCounters {
+	counters := last
+	counters.userSeconds = float64(p.stat.UTime) / processUserHZ
+	counters.systemSeconds = float64(p.stat.STime) / processUserHZ
+	s.processes++
+	s.threads += p.stat.NumThreads
+	s.rss += uint64(p.stat.ResidentMemory())
+
+	if rollup, err := p.ProcSMapsRollup(); err == nil {
+		s.pss += rollup.Pss
+	} else {
+		c.logger.Debug("couldn't read memory maps of process", "pid", p.PID, "err", err)
+	}
+	if fds, err := p.FileDescriptorsLen(); err == nil {
+		s.fds += fds
+	} else {
+		c.logger.Debug("couldn't read file descriptors of process", "pid", p.PID, "err", err)
+	}
+	if io, err := p.IO(); err == nil {
+		counters.readBytes = io.ReadBytes
+		counters.writtenBytes = io.WriteBytes
+	} else {
+		c.logger.Debug("couldn't read I/O of process", "pid", p.PID, "err", err)
+	}
+	if status, err := p.Status(); err == nil {
+		counters.voluntarySwitches = status.VoluntaryCtxtSwitches
+		counters.involuntarySwitches = status.NonVoluntaryCtxtSwitches
+	} else {
+		c.logger.Debug("couldn't read status of process", "pid", p.PID, "err", err)
+	}
+	s.add(counters)
+	return counters
+}
+
+// readUsers maps the UIDs to the user names of the host's /etc/passwd.
+func (c *processCollector) readUsers() map[uint64]string {
+	users := map[uint64]string{}
+	f, err := os.Open(c.paths.rootfsFilePath("etc/passwd"))
+	if err != nil {
+		c.logger.Debug("couldn't read users", "err", err)
+		return users
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 {
+			continue
+		}
+		if uid, err := strconv.ParseUint(fields[2], 10, 64); err == nil {
+			users[uid] = fields[0]
+		}
+	}
+	return users
+}
@@ -23,6 +23,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,6 +38,12 @@ type processCollector struct {
 	procsState   *prometheus.Desc
 	pidUsed      *prometheus.Desc
 	pidMax       *prometheus.Desc
+	groupRules   []processGroupRule
+	groupDescs   processGroupDescs
+	groupMtx     sync.Mutex
+	groupMembers map[processGroupMember]processGroupMemberStats
+	groupExited  map[string]*processGroupCounters
+	paths        Paths
 	logger       *slog.Logger
 }
 
@@ -50,6 +57,10 @@ func NewProcessStatCollector(logger *slog.Logger) (Collector, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
+	groupRules, err := parseProcessGroupRules(*processGroupFlags)
+	if err != nil {
+		return nil, err
+	}
 	subsystem := "processes"
 	return &processCollector{
 		fs: fs,
@@ -79,7 +90,10 @@ func NewProcessStatCollector(logger *slog.Logger) (Collector, error) {
 		pidMax: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "max_processes"),
 			"Number of max PIDs limit", nil, nil,
 		),
-		logger: logger,
+		groupRules: groupRules,
+		groupDescs: newProcessGroupDescs(subsystem),
+		paths:      currentPaths(),
+		logger:     logger,
 	}, nil
 }
 func (c *processCollector) Update(ch chan<- prometheus.Metric) error {
@@ -89,7 +103,7 @@ func (c *processCollector) Update(ch chan<- prometheus.Metric) error {
 	}
 
 	ch <- prometheus.MustNewConstMetric(c.threadAlloc, prometheus.GaugeValue, float64(threads))
-	maxThreads, err := readUintFromFile(procFilePath("sys/kernel/threads-max"))
+	maxThreads, err := readUintFromFile(c.paths.procFilePath("sys/kernel/threads-max"))
 	if err != nil {
 		return fmt.Errorf("unable to retrieve limit number of threads: %w", err)
 	}
@@ -103,13 +117,16 @@ func (c *processCollector) Update(ch chan<- prometheus.Metric) error {
 		ch <- prometheus.MustNewConstMetric(c.threadsState, prometheus.GaugeValue, float64(threadStates[state]), state)
 	}
 
-	pidM, err := readUintFromFile(procFilePath("sys/kernel/pid_max"))
+	pidM, err := readUintFromFile(c.paths.procFilePath("sys/kernel/pid_max"))
 	if err != nil {
 		return fmt.Errorf("unable to retrieve limit number of maximum pids allowed: %w", err)
 	}
 	ch <- prometheus.MustNewConstMetric(c.pidUsed, prometheus.GaugeValue, float64(pids))
 	ch <- prometheus.MustNewConstMetric(c.pidMax, prometheus.GaugeValue, float64(pidM))
 
+	if len(c.groupRules) > 0 {
+		return c.updateGroups(ch)
+	}
 	return nil
 }
 
@@ -146,7 +163,7 @@ func (c *processCollector) getAllocatedThreads() (int, map[string]int32, int, ma
 }
 
 func (c *processCollector) getThreadStates(pid int, pidStat procfs.ProcStat, threadStates map[string]int32) error {
-	fs, err := procfs.NewFS(procFilePath(path.Join(strconv.Itoa(pid), "task")))
+	fs, err := procfs.NewFS(c.paths.procFilePath(path.Join(strconv.Itoa(pid), "task")))
 	if err != nil {
 		if c.isIgnoredError(err) {
 			c.logger.Debug("file not found when retrieving tasks for pid", "pid", pid, "err", err)
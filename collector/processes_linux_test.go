@@ -16,11 +16,17 @@
 package collector
 
 import (
+	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/procfs"
 )
 
@@ -53,3 +59,177 @@ func TestReadProcessStatus(t *testing.T) {
 		t.Fatalf("Total running pids cannot be greater than %d or equals to 0", maxPid)
 	}
 }
+
+type testProcessGroupCollector struct {
+	c *processCollector
+}
+
+func (c testProcessGroupCollector) Collect(ch chan<- prometheus.Metric) {
+	if err := c.c.updateGroups(ch); err != nil {
+		panic(err)
+	}
+}
+
+func (c testProcessGroupCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func writeTestProcess(t *testing.T, dir string, pid int, comm string, uid, utime, stime, threads, rss, fds int, cgroup string) {
+	t.Helper()
+	files := map[string]string{
+		"stat": fmt.Sprintf("%d (%s) S 1 %d %d 0 -1 4194560 0 0 0 0 %d %d 0 0 20 0 %d 0 29 109604864 %d 18446744073709551615 1 1 0 0 0 0 0 4096 1260 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n",
+			pid, comm, pid, pid, utime, stime, threads, rss),
+		"status":       fmt.Sprintf("Name:\t%s\nUid:\t%d\t%d\t%d\t%d\nvoluntary_ctxt_switches:\t%d\nnonvoluntary_ctxt_switches:\t%d\n", comm, uid, uid, uid, uid, 10*pid, pid),
+		"io":           fmt.Sprintf("rchar: 0\nwchar: 0\nsyscr: 0\nsyscw: 0\nread_bytes: %d\nwrite_bytes: %d\ncancelled_write_bytes: 0\n", 4096*pid, 1024*pid),
+		"cgroup":       "0::" + cgroup + "\n",
+		"smaps_rollup": "00400000-7ffd1c5fe000 ---p 00000000 00:00 0                          [rollup]\nRss:                1024 kB\nPss:                 512 kB\n",
+	}
+	procDir := filepath.Join(dir, fmt.Sprint(pid))
+	if err := os.MkdirAll(filepath.Join(procDir, "fd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(procDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for fd := range fds {
+		if err := os.WriteFile(filepath.Join(procDir, "fd", fmt.Sprint(fd)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProcessGroups(t *testing.T) {
+	procDir, rootDir := t.TempDir(), t.TempDir()
+	writeTestProcess(t, procDir, 100, "nginx", 33, 150, 50, 1, 100, 3, "/system.slice/nginx.service")
+	writeTestProcess(t, procDir, 101, "nginx", 33, 250, 150, 2, 200, 5, "/system.slice/nginx.service")
+	writeTestProcess(t, procDir, 200, "postgres", 70, 1000, 300, 1, 1000, 10, "/system.slice/postgresql.service")
+	writeTestProcess(t, procDir, 300, "bash", 1000, 5, 5, 1, 50, 4, "/user.slice/user-1000.slice/session-1.scope")
+	if err := os.MkdirAll(filepath.Join(rootDir, "etc"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	passwd := "root:x:0:0:root:/root:/bin/bash\npostgres:x:70:70::/var/lib/postgres:/bin/sh\n"
+	if err := os.WriteFile(filepath.Join(rootDir, "etc", "passwd"), []byte(passwd), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := parseProcessGroupRules([]string{
+		"web=comm:nginx|apache2",
+		"db=user:postgres",
+		"users=cgroup:/user\\.slice/.*",
+		"cron=comm:cron",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := procfs.NewFS(procDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &processCollector{
+		fs:         fs,
+		groupRules: rules,
+		groupDescs: newProcessGroupDescs("processes"),
+		paths:      Paths{Procfs: procDir, Rootfs: rootDir},
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	page := os.Getpagesize()
+	want := fmt.Sprintf(`# HELP node_processes_group_cpu_seconds_total CPU time spent by the processes in the group, by mode.
+# TYPE node_processes_group_cpu_seconds_total counter
+node_processes_group_cpu_seconds_total{group="cron",mode="system"} 0
+node_processes_group_cpu_seconds_total{group="cron",mode="user"} 0
+node_processes_group_cpu_seconds_total{group="db",mode="system"} 3
+node_processes_group_cpu_seconds_total{group="db",mode="user"} 10
+node_processes_group_cpu_seconds_total{group="users",mode="system"} 0.05
+node_processes_group_cpu_seconds_total{group="users",mode="user"} 0.05
+node_processes_group_cpu_seconds_total{group="web",mode="system"} 2
+node_processes_group_cpu_seconds_total{group="web",mode="user"} 4
+# HELP node_processes_group_open_fds Number of file descriptors opened by the processes in the group.
+# TYPE node_processes_group_open_fds gauge
+node_processes_group_open_fds{group="cron"} 0
+node_processes_group_open_fds{group="db"} 10
+node_processes_group_open_fds{group="users"} 4
+node_processes_group_open_fds{group="web"} 8
+# HELP node_processes_group_processes Number of processes in the group.
+# TYPE node_processes_group_processes gauge
+node_processes_group_processes{group="cron"} 0
+node_processes_group_processes{group="db"} 1
+node_processes_group_processes{group="users"} 1
+node_processes_group_processes{group="web"} 2
+# HELP node_processes_group_proportional_memory_bytes Proportional set size of the processes in the group, shared pages being divided among their users.
+# TYPE node_processes_group_proportional_memory_bytes gauge
+node_processes_group_proportional_memory_bytes{group="cron"} 0
+node_processes_group_proportional_memory_bytes{group="db"} 524288
+node_processes_group_proportional_memory_bytes{group="users"} 524288
+node_processes_group_proportional_memory_bytes{group="web"} 1.048576e+06
+# HELP node_processes_group_read_bytes_total Number of bytes read from storage by the processes in the group.
+# TYPE node_processes_group_read_bytes_total counter
+node_processes_group_read_bytes_total{group="cron"} 0
+node_processes_group_read_bytes_total{group="db"} 819200
+node_processes_group_read_bytes_total{group="users"} 1.2288e+06
+node_processes_group_read_bytes_total{group="web"} 823296
+# HELP node_processes_group_resident_memory_bytes Resident memory of the processes in the group.
+# TYPE node_processes_group_resident_memory_bytes gauge
+node_processes_group_resident_memory_bytes{group="cron"} 0
+node_processes_group_resident_memory_bytes{group="db"} %d
+node_processes_group_resident_memory_bytes{group="users"} %d
+node_processes_group_resident_memory_bytes{group="web"} %d
+# HELP node_processes_group_threads Number of threads of the processes in the group.
+# TYPE node_processes_group_threads gauge
+node_processes_group_threads{group="cron"} 0
+node_processes_group_threads{group="db"} 1
+node_processes_group_threads{group="users"} 1
+node_processes_group_threads{group="web"} 3
+`, 1000*page, 50*page, 300*page)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(testProcessGroupCollector{c})
+	err = testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"node_processes_group_cpu_seconds_total", "node_processes_group_open_fds", "node_processes_group_processes",
+		"node_processes_group_proportional_memory_bytes", "node_processes_group_read_bytes_total",
+		"node_processes_group_resident_memory_bytes", "node_processes_group_threads")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The counters of the processes that exited are kept in their group.
+	if err := os.RemoveAll(filepath.Join(procDir, "101")); err != nil {
+		t.Fatal(err)
+	}
+	writeTestProcess(t, procDir, 100, "nginx", 33, 200, 50, 1, 100, 3, "/system.slice/nginx.service")
+	want = `# HELP node_processes_group_cpu_seconds_total CPU time spent by the processes in the group, by mode.
+# TYPE node_processes_group_cpu_seconds_total counter
+node_processes_group_cpu_seconds_total{group="cron",mode="system"} 0
+node_processes_group_cpu_seconds_total{group="cron",mode="user"} 0
+node_processes_group_cpu_seconds_total{group="db",mode="system"} 3
+node_processes_group_cpu_seconds_total{group="db",mode="user"} 10
+node_processes_group_cpu_seconds_total{group="users",mode="system"} 0.05
+node_processes_group_cpu_seconds_total{group="users",mode="user"} 0.05
+node_processes_group_cpu_seconds_total{group="web",mode="system"} 2
+node_processes_group_cpu_seconds_total{group="web",mode="user"} 4.5
+# HELP node_processes_group_processes Number of processes in the group.
+# TYPE node_processes_group_processes gauge
+node_processes_group_processes{group="cron"} 0
+node_processes_group_processes{group="db"} 1
+node_processes_group_processes{group="users"} 1
+node_processes_group_processes{group="web"} 1
+# HELP node_processes_group_read_bytes_total Number of bytes read from storage by the processes in the group.
+# TYPE node_processes_group_read_bytes_total counter
+node_processes_group_read_bytes_total{group="cron"} 0
+node_processes_group_read_bytes_total{group="db"} 819200
+node_processes_group_read_bytes_total{group="users"} 1.2288e+06
+node_processes_group_read_bytes_total{group="web"} 823296
+`
+	err = testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"node_processes_group_cpu_seconds_total", "node_processes_group_processes", "node_processes_group_read_bytes_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, flag := range []string{"web", "web=comm", "web=pid:1", "web=comm:("} {
+		if _, err := parseProcessGroupRules([]string{flag}); err == nil {
+			t.Errorf("%s: expected error", flag)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/procfs"
+)
+
+// readPSIStats reads a pressure stall information file, like the ones in
+// /proc/pressure or in the cgroup v2 directories:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readPSIStats(path string) (procfs.PSIStats, error) {
+	var stats procfs.PSIStats
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return stats, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		psi := &procfs.PSILine{}
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return stats, fmt.Errorf("%s: malformed field %q", path, field)
+			}
+			switch key {
+			case "avg10":
+				psi.Avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				psi.Avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				psi.Avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				psi.Total, err = strconv.ParseUint(value, 10, 64)
+			}
+			if err != nil {
+				return stats, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		switch fields[0] {
+		case "some":
+			stats.Some = psi
+		case "full":
+			stats.Full = psi
+		}
+	}
+	return stats, nil
+}
@@ -30,6 +30,7 @@ import (
 type qdiscStatCollector struct {
 	logger       *slog.Logger
 	deviceFilter deviceFilter
+	fixtures     string
 	bytes        typedDesc
 	packets      typedDesc
 	drops        typedDesc
@@ -113,6 +114,7 @@ func NewQdiscStatCollector(logger *slog.Logger) (Collector, error) {
 		), prometheus.GaugeValue},
 		logger:       logger,
 		deviceFilter: newDeviceFilter(*collectorQdiscDeviceExclude, *collectorQdiscDeviceInclude),
+		fixtures:     *collectorQdisc,
 	}, nil
 }
 
@@ -132,12 +134,10 @@ func (c *qdiscStatCollector) Update(ch chan<- prometheus.Metric) error {
 	var msgs []qdisc.QdiscInfo
 	var err error
 
-	fixtures := *collectorQdisc
-
-	if fixtures == "" {
+	if c.fixtures == "" {
 		msgs, err = qdisc.Get()
 	} else {
-		msgs, err = testQdiscGet(fixtures)
+		msgs, err = testQdiscGet(c.fixtures)
 	}
 
 	if err != nil {
@@ -30,8 +30,9 @@ import (
 const raplCollectorSubsystem = "rapl"
 
 type raplCollector struct {
-	fs     sysfs.FS
-	logger *slog.Logger
+	fs        sysfs.FS
+	zoneLabel bool
+	logger    *slog.Logger
 
 	joulesMetricDesc *prometheus.Desc
 }
@@ -60,6 +61,7 @@ func NewRaplCollector(logger *slog.Logger) (Collector, error) {
 
 	collector := raplCollector{
 		fs:               fs,
+		zoneLabel:        *raplZoneLabel,
 		logger:           logger,
 		joulesMetricDesc: joulesMetricDesc,
 	}
@@ -94,7 +96,7 @@ func (c *raplCollector) Update(ch chan<- prometheus.Metric) error {
 
 		joules := float64(microJoules) / 1000000.0
 
-		if *raplZoneLabel {
+		if c.zoneLabel {
 			ch <- c.joulesMetricWithZoneLabel(rz, joules)
 		} else {
 			ch <- c.joulesMetric(rz, joules)
@@ -0,0 +1,261 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// RelabelAction is the action of a relabeling rule.
+type RelabelAction string
+
+// The supported relabeling actions, a subset of Prometheus'.
+const (
+	// RelabelReplace sets the target label to the replacement if the
+	// regular expression matches the source labels' values.
+	RelabelReplace RelabelAction = "replace"
+	// RelabelKeep drops the series whose source labels' values don't match.
+	RelabelKeep RelabelAction = "keep"
+	// RelabelDrop drops the series whose source labels' values match.
+	RelabelDrop RelabelAction = "drop"
+	// RelabelLabelDrop removes the labels whose names match.
+	RelabelLabelDrop RelabelAction = "labeldrop"
+)
+
+// RelabelConfig is a relabeling rule as in Prometheus' metric_relabel_configs.
+// The metric name is available as the __name__ label. Labels starting with
+// "__" other than __name__ are removed once all rules were applied, so they
+// can hold temporary values.
+type RelabelConfig struct {
+	SourceLabels model.LabelNames `yaml:"source_labels,flow,omitempty"`
+	Separator    string           `yaml:"separator,omitempty"`
+	Regex        Regexp           `yaml:"regex,omitempty"`
+	TargetLabel  string           `yaml:"target_label,omitempty"`
+	Replacement  string           `yaml:"replacement,omitempty"`
+	Action       RelabelAction    `yaml:"action,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface, applying the same
+// defaults as Prometheus.
+func (c *RelabelConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain RelabelConfig
+	*c = RelabelConfig{
+		Separator:   ";",
+		Regex:       MustNewRegexp("(.*)"),
+		Replacement: "$1",
+		Action:      RelabelReplace,
+	}
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	switch c.Action {
+	case RelabelReplace:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("relabel action %s requires a target_label", c.Action)
+		}
+		if !strings.Contains(c.TargetLabel, "$") && !model.LegacyValidation.IsValidLabelName(c.TargetLabel) {
+			return fmt.Errorf("%q is not a valid target_label", c.TargetLabel)
+		}
+	case RelabelKeep, RelabelDrop:
+		if len(c.SourceLabels) == 0 {
+			return fmt.Errorf("relabel action %s requires source_labels", c.Action)
+		}
+	case RelabelLabelDrop:
+		if len(c.SourceLabels) > 0 || c.TargetLabel != "" || c.Separator != ";" || c.Replacement != "$1" {
+			return fmt.Errorf("relabel action %s only takes a regex", c.Action)
+		}
+	default:
+		return fmt.Errorf("unsupported relabel action %q, must be one of replace, keep, drop or labeldrop", c.Action)
+	}
+	return nil
+}
+
+// Regexp is a regular expression anchored at both ends, as in Prometheus'
+// relabeling rules.
+type Regexp struct {
+	*regexp.Regexp
+	original string
+}
+
+// NewRegexp compiles the anchored form of s.
+func NewRegexp(s string) (Regexp, error) {
+	re, err := regexp.Compile("^(?:" + s + ")$")
+	return Regexp{Regexp: re, original: s}, err
+}
+
+// MustNewRegexp is like NewRegexp but panics on an invalid expression.
+func MustNewRegexp(s string) Regexp {
+	re, err := NewRegexp(s)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (re *Regexp) UnmarshalYAML(unmarshal func(any) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	r, err := NewRegexp(s)
+	if err != nil {
+		return err
+	}
+	*re = r
+	return nil
+}
+
+// String returns the expression as configured, without the anchors.
+func (re Regexp) String() string {
+	return re.original
+}
+
+// relabel applies cfgs to the labels in place and returns false if the series
+// is to be dropped.
+func relabel(labels map[string]string, cfgs []*RelabelConfig) bool {
+	for _, cfg := range cfgs {
+		values := make([]string, 0, len(cfg.SourceLabels))
+		for _, name := range cfg.SourceLabels {
+			values = append(values, labels[string(name)])
+		}
+		value := strings.Join(values, cfg.Separator)
+
+		switch cfg.Action {
+		case RelabelKeep:
+			if !cfg.Regex.MatchString(value) {
+				return false
+			}
+		case RelabelDrop:
+			if cfg.Regex.MatchString(value) {
+				return false
+			}
+		case RelabelReplace:
+			indexes := cfg.Regex.FindStringSubmatchIndex(value)
+			if indexes == nil {
+				continue
+			}
+			target := string(cfg.Regex.ExpandString(nil, cfg.TargetLabel, value, indexes))
+			if !model.LegacyValidation.IsValidLabelName(target) {
+				continue
+			}
+			replacement := string(cfg.Regex.ExpandString(nil, cfg.Replacement, value, indexes))
+			if replacement == "" {
+				delete(labels, target)
+				continue
+			}
+			labels[target] = replacement
+		case RelabelLabelDrop:
+			for name := range labels {
+				if cfg.Regex.MatchString(name) {
+					delete(labels, name)
+				}
+			}
+		}
+	}
+	return true
+}
+
+// relabelMetric applies cfgs to a metric. It returns the metric unchanged if
+// no rule modified its labels, and false if it is to be dropped.
+func relabelMetric(m prometheus.Metric, cfgs []*RelabelConfig) (prometheus.Metric, bool) {
+	if len(cfgs) == 0 {
+		return m, true
+	}
+	name, help, ok := parseDesc(m.Desc())
+	pb := &dto.Metric{}
+	if !ok || m.Write(pb) != nil {
+		// Invalid metrics are left to the registry to report.
+		return m, true
+	}
+
+	labels := make(map[string]string, len(pb.Label)+1)
+	for _, lp := range pb.Label {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	labels[model.MetricNameLabel] = name
+	original := maps.Clone(labels)
+	if !relabel(labels, cfgs) {
+		return nil, false
+	}
+	if maps.Equal(labels, original) {
+		return m, true
+	}
+
+	name = labels[model.MetricNameLabel]
+	if name == "" {
+		return nil, false
+	}
+	constLabels := prometheus.Labels{}
+	pb.Label = pb.Label[:0]
+	for _, label := range slices.Sorted(maps.Keys(labels)) {
+		if strings.HasPrefix(label, "__") {
+			continue
+		}
+		constLabels[label] = labels[label]
+		pb.Label = append(pb.Label, &dto.LabelPair{Name: proto.String(label), Value: proto.String(labels[label])})
+	}
+	return relabeledMetric{desc: prometheus.NewDesc(name, help, nil, constLabels), metric: pb}, true
+}
+
+// parseDesc returns the name and help of a descriptor, which client_golang
+// only exposes through its string representation.
+func parseDesc(desc *prometheus.Desc) (name, help string, ok bool) {
+	s, ok := strings.CutPrefix(desc.String(), "Desc{fqName: ")
+	if !ok {
+		return "", "", false
+	}
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", "", false
+	}
+	name, _ = strconv.Unquote(quoted)
+	s, ok = strings.CutPrefix(s[len(quoted):], ", help: ")
+	if !ok {
+		return "", "", false
+	}
+	quoted, err = strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", "", false
+	}
+	help, _ = strconv.Unquote(quoted)
+	return name, help, true
+}
+
+// relabeledMetric is a metric whose name or labels were changed by
+// relabeling. All its labels are constant labels of its descriptor.
+type relabeledMetric struct {
+	desc   *prometheus.Desc
+	metric *dto.Metric
+}
+
+func (m relabeledMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+func (m relabeledMetric) Write(out *dto.Metric) error {
+	proto.Reset(out)
+	proto.Merge(out, m.metric)
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"go.yaml.in/yaml/v2"
+)
+
+func mustParseRelabelConfigs(t *testing.T, s string) []*RelabelConfig {
+	t.Helper()
+	var cfgs []*RelabelConfig
+	if err := yaml.UnmarshalStrict([]byte(s), &cfgs); err != nil {
+		t.Fatal(err)
+	}
+	return cfgs
+}
+
+func TestRelabelConfigValidation(t *testing.T) {
+	for _, s := range []string{
+		`[{action: replace}]`,
+		`[{action: replace, target_label: "1abc"}]`,
+		`[{action: drop}]`,
+		`[{action: labeldrop, source_labels: [device]}]`,
+		`[{action: hashmod, source_labels: [device]}]`,
+		`[{action: drop, source_labels: [device], regex: "("}]`,
+	} {
+		var cfgs []*RelabelConfig
+		if err := yaml.UnmarshalStrict([]byte(s), &cfgs); err == nil {
+			t.Errorf("expected error parsing %s", s)
+		}
+	}
+}
+
+func TestRelabel(t *testing.T) {
+	cfgs := mustParseRelabelConfigs(t, `
+- source_labels: [__name__, device]
+  regex: node_disk_(.+);(sd.)
+  target_label: __tmp_disk
+  replacement: $2
+- source_labels: [__tmp_disk]
+  regex: sda
+  target_label: primary
+  replacement: "yes"
+- source_labels: [mode]
+  target_label: mode
+  replacement: ""
+- regex: __tmp_.*|serial
+  action: labeldrop
+- source_labels: [device]
+  regex: sd.|nvme.+
+  action: keep
+`)
+	for _, tc := range []struct {
+		in, want map[string]string
+	}{
+		{
+			in:   map[string]string{"__name__": "node_disk_io_now", "device": "sda", "serial": "1234"},
+			want: map[string]string{"__name__": "node_disk_io_now", "device": "sda", "primary": "yes"},
+		},
+		{
+			in:   map[string]string{"__name__": "node_disk_io_now", "device": "nvme0n1", "mode": "x"},
+			want: map[string]string{"__name__": "node_disk_io_now", "device": "nvme0n1"},
+		},
+		{
+			in: map[string]string{"__name__": "node_disk_io_now", "device": "dm-0"},
+		},
+	} {
+		labels := maps.Clone(tc.in)
+		keep := relabel(labels, cfgs)
+		if tc.want == nil {
+			if keep {
+				t.Errorf("%v: want dropped, got %v", tc.in, labels)
+			}
+			continue
+		}
+		if !keep || !maps.Equal(labels, tc.want) {
+			t.Errorf("%v: want %v, got %v (kept %t)", tc.in, tc.want, labels, keep)
+		}
+	}
+}
+
+// TestRelabelE2EOutput applies rules to the metrics of the end-to-end test
+// and checks that exactly the targeted series are dropped or rewritten.
+func TestRelabelE2EOutput(t *testing.T) {
+	f, err := os.Open("fixtures/e2e-output.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	parser := expfmt.NewTextParser(model.LegacyValidation)
+	families, err := parser.TextToMetricFamilies(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgs := mustParseRelabelConfigs(t, `
+- source_labels: [__name__]
+  regex: node_cpu_guest_seconds_total
+  action: drop
+- source_labels: [__name__, device]
+  regex: node_network_.+;lo
+  action: drop
+- source_labels: [__name__]
+  regex: node_load(.+)
+  target_label: __name__
+  replacement: node_load_${1}m
+- regex: fstype
+  action: labeldrop
+`)
+
+	var metrics, want []prometheus.Metric
+	for _, name := range slices.Sorted(maps.Keys(families)) {
+		mf := families[name]
+		for _, m := range mf.Metric {
+			labels := prometheus.Labels{}
+			for _, lp := range m.Label {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			metric := relabeledMetric{desc: prometheus.NewDesc(name, mf.GetHelp(), nil, labels), metric: m}
+			metrics = append(metrics, metric)
+
+			switch {
+			case name == "node_cpu_guest_seconds_total":
+			case strings.HasPrefix(name, "node_network_") && labels["device"] == "lo":
+			case strings.HasPrefix(name, "node_load"):
+				suffix := strings.TrimPrefix(name, "node_load")
+				desc := prometheus.NewDesc("node_load_"+suffix+"m", mf.GetHelp(), nil, labels)
+				want = append(want, relabeledMetric{desc: desc, metric: m})
+			case labels["fstype"] != "":
+				delete(labels, "fstype")
+				desc := prometheus.NewDesc(name, mf.GetHelp(), nil, labels)
+				want = append(want, relabeledMetric{desc: desc, metric: m})
+			default:
+				want = append(want, metric)
+			}
+		}
+	}
+	if len(want) == len(metrics) {
+		t.Fatal("no series in the fixture output targeted by the rules")
+	}
+
+	var got []prometheus.Metric
+	for _, m := range metrics {
+		if m, ok := relabelMetric(m, cfgs); ok {
+			got = append(got, m)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %d series, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if want, got := want[i].Desc().String(), got[i].Desc().String(); want != got {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	}
+}
+
+func TestNodeCollectorRelabel(t *testing.T) {
+	nc := &NodeCollector{
+		Collectors: map[string]Collector{
+			"a": testSeriesCollector{"a", 3},
+			"b": testSeriesCollector{"b", 3},
+		},
+		relabelConfigs: map[string][]*RelabelConfig{
+			"a": mustParseRelabelConfigs(t, `[{source_labels: [i], regex: "0|1", action: drop}]`),
+		},
+		nodeRelabelConfigs: mustParseRelabelConfigs(t, `
+- source_labels: [__name__, collector]
+  regex: node_scrape_collector_series;b
+  action: drop
+- source_labels: [i]
+  regex: "2"
+  target_label: last
+  replacement: "true"
+`),
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	want := `# HELP node_scrape_collector_series node_exporter: Number of metrics a collector exposed, histograms and summaries counting once.
+# TYPE node_scrape_collector_series gauge
+node_scrape_collector_series{collector="a"} 1
+# HELP node_test_series Test series.
+# TYPE node_test_series gauge
+node_test_series{collector="a",i="2",last="true"} 1
+node_test_series{collector="b",i="0"} 1
+node_test_series{collector="b",i="1"} 1
+node_test_series{collector="b",i="2",last="true"} 1
+`
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(nc)
+	err := testutil.GatherAndCompare(reg, strings.NewReader(want), "node_scrape_collector_series", "node_test_series")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noscript && !notextfile
+
+package collector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	scriptSubsystem = "script"
+	// scriptPath is the PATH scripts are run with.
+	scriptPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+	// scriptMaxOutput caps the output read from a script.
+	scriptMaxOutput = 16 << 20
+)
+
+var (
+	scriptDirectory = kingpin.Flag("collector.script.directory", "Directory of executables to run, whose output in the text format is exposed.").Default("").String()
+	scriptInterval  = kingpin.Flag("collector.script.interval", "Interval at which the scripts are run, unless --collector.collect-interval is set for the script collector.").Default("1m").Duration()
+	scriptTimeout   = kingpin.Flag("collector.script.timeout", "Timeout after which a script is killed.").Default("30s").Duration()
+	scriptEnv       = kingpin.Flag("collector.script.env", "Environment variable to pass to the scripts, as <name>=<value>. Scripts only get PATH otherwise. (repeatable)").PlaceHolder("<name>=<value>").Strings()
+)
+
+// scriptCollector runs the scripts on every update. It runs in the
+// background on an interval like the collectors given a collect interval, so
+// that scrapes don't run the scripts.
+type scriptCollector struct {
+	dir     string
+	timeout time.Duration
+	env     []string
+	logger  *slog.Logger
+
+	exitCode    *prometheus.Desc
+	duration    *prometheus.Desc
+	lastSuccess *prometheus.Desc
+
+	mtx     sync.Mutex
+	results map[string]*scriptResult
+}
+
+// scriptResult is the outcome of the last run of a script.
+type scriptResult struct {
+	exitCode    int
+	duration    time.Duration
+	lastSuccess time.Time
+	// families are the metrics of the last run, nil if it failed.
+	families map[string]*dto.MetricFamily
+}
+
+func init() {
+	registerCollector("script", defaultDisabled, NewScriptCollector)
+}
+
+// NewScriptCollector returns a new Collector running the executables in the
+// script directory on an interval and exposing their output. The interval is
+// --collector.script.interval, unless --collector.collect-interval sets one,
+// in which case newCollector runs the collector in the background.
+func NewScriptCollector(logger *slog.Logger) (Collector, error) {
+	if *scriptDirectory == "" {
+		return nil, errors.New("--collector.script.directory must be set")
+	}
+	for _, e := range *scriptEnv {
+		if !strings.Contains(e, "=") {
+			return nil, fmt.Errorf("expected <name>=<value>, got %q", e)
+		}
+	}
+	if *scriptInterval <= 0 {
+		return nil, errors.New("--collector.script.interval must be positive")
+	}
+	c := newScriptCollector(*scriptDirectory, *scriptTimeout, logger)
+	c.env = append(c.env, *scriptEnv...)
+	if _, ok := (*collectorIntervals)["script"]; ok {
+		return c, nil
+	}
+	return newBackgroundCollector("script", c, *scriptInterval, timeoutFor("script"), logger), nil
+}
+
+func newScriptCollector(dir string, timeout time.Duration, logger *slog.Logger) *scriptCollector {
+	return &scriptCollector{
+		dir:     dir,
+		timeout: timeout,
+		env:     []string{"PATH=" + scriptPath},
+		logger:  logger,
+		exitCode: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, scriptSubsystem, "exit_code"),
+			"Exit code of the last run of the script, -1 if it was killed or couldn't be started.",
+			[]string{"script"}, nil,
+		),
+		duration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, scriptSubsystem, "duration_seconds"),
+			"Duration of the last run of the script.",
+			[]string{"script"}, nil,
+		),
+		lastSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, scriptSubsystem, "last_success_timestamp_seconds"),
+			"Time the script last ran successfully, 0 if it never did.",
+			[]string{"script"}, nil,
+		),
+		results: map[string]*scriptResult{},
+	}
+}
+
+// runScripts runs the executables in the directory concurrently and stores
+// their results.
+func (c *scriptCollector) runScripts(ctx context.Context) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		c.logger.Error("failed to read script directory", "path", c.dir, "err", err)
+	}
+	var (
+		wg      sync.WaitGroup
+		results sync.Map
+	)
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || strings.HasPrefix(e.Name(), ".") || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			results.Store(name, c.runScript(ctx, name))
+		}(e.Name())
+	}
+	wg.Wait()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	previous := c.results
+	c.results = map[string]*scriptResult{}
+	results.Range(func(k, v any) bool {
+		name, r := k.(string), v.(*scriptResult)
+		if r.lastSuccess.IsZero() {
+			if p, ok := previous[name]; ok {
+				r.lastSuccess = p.lastSuccess
+			}
+		}
+		c.results[name] = r
+		return true
+	})
+}
+
+func (c *scriptCollector) runScript(ctx context.Context, name string) *scriptResult {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, filepath.Join(c.dir, name))
+	cmd.Dir = c.dir
+	cmd.Env = c.env
+	output := &limitedWriter{w: &stdout, n: scriptMaxOutput}
+	cmd.Stdout = output
+	cmd.Stderr = &limitedWriter{w: &stderr, n: 4096}
+	killProcessGroup(cmd)
+	cmd.WaitDelay = time.Second
+
+	begin := time.Now()
+	err := cmd.Run()
+	r := &scriptResult{exitCode: cmd.ProcessState.ExitCode(), duration: time.Since(begin)}
+	logger := c.logger.With("script", name)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+		logger.Error("script failed", "err", err, "stderr", strings.TrimSpace(stderr.String()))
+		return r
+	}
+	if output.dropped > 0 {
+		// Parsing the output cut off could yield partial metrics.
+		logger.Error("script output too large", "max_bytes", scriptMaxOutput, "dropped_bytes", output.dropped)
+		return r
+	}
+
+	parser := expfmt.NewTextParser(model.LegacyValidation)
+	families, err := parser.TextToMetricFamilies(&stdout)
+	if err == nil && hasTimestamps(families) {
+		err = errors.New("output contains unsupported client-side timestamps")
+	}
+	if err != nil {
+		logger.Error("failed to parse script output", "err", err)
+		return r
+	}
+	r.families = families
+	r.lastSuccess = begin.Add(r.duration)
+	return r
+}
+
+// Update implements Collector.
+func (c *scriptCollector) Update(ch chan<- prometheus.Metric) error {
+	return c.UpdateContext(context.Background(), ch)
+}
+
+// UpdateContext runs the scripts and exposes their results. Scripts still
+// running once ctx is done are killed.
+func (c *scriptCollector) UpdateContext(ctx context.Context, ch chan<- prometheus.Metric) error {
+	c.runScripts(ctx)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	names := make([]string, 0, len(c.results))
+	for name := range c.results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// The scripts may expose the same metrics, which need the same type
+	// and help text.
+	seen := map[string]*dto.MetricFamily{}
+	for _, name := range names {
+		r := c.results[name]
+		var lastSuccess float64
+		if !r.lastSuccess.IsZero() {
+			lastSuccess = float64(r.lastSuccess.UnixNano()) / 1e9
+		}
+		ch <- prometheus.MustNewConstMetric(c.exitCode, prometheus.GaugeValue, float64(r.exitCode), name)
+		ch <- prometheus.MustNewConstMetric(c.duration, prometheus.GaugeValue, r.duration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(c.lastSuccess, prometheus.GaugeValue, lastSuccess, name)
+
+		for _, family := range r.families {
+			// Set the help text on a copy, the results are kept until the
+			// next run.
+			mf := &dto.MetricFamily{Name: family.Name, Help: family.Help, Type: family.Type, Unit: family.Unit, Metric: family.Metric}
+			if mf.Help == nil {
+				mf.Help = proto.String("Metric read from script " + name)
+			}
+			if first, ok := seen[mf.GetName()]; ok {
+				if first.GetType() != mf.GetType() {
+					c.logger.Error("inconsistent metric type", "metric", mf.GetName(), "script", name)
+					continue
+				}
+				mf.Help = first.Help
+			} else {
+				seen[mf.GetName()] = mf
+			}
+			if err := convertMetricFamily(mf, ch, false, c.logger); err != nil {
+				c.logger.Error("invalid script metrics", "metric", mf.GetName(), "script", name, "err", err)
+			}
+		}
+	}
+	return nil
+}
+
+// limitedWriter writes up to n bytes to w and discards the rest, counting
+// them in dropped.
+type limitedWriter struct {
+	w       io.Writer
+	n       int
+	dropped int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n <= 0 {
+		l.dropped += len(p)
+		return len(p), nil
+	}
+	written := p
+	if len(written) > l.n {
+		written = written[:l.n]
+		l.dropped += len(p) - l.n
+	}
+	n, err := l.w.Write(written)
+	l.n -= n
+	if err != nil {
+		return n, err
+	}
+	return len(p), nil
+}
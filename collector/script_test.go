@@ -0,0 +1,130 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noscript && !notextfile
+
+package collector
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestScriptCollector(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, mode os.FileMode) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+content), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("ok", "echo \"# HELP script_env Environment of the script.\"\necho \"script_env{path=\\\"$PATH\\\",home=\\\"$HOME\\\",extra=\\\"$EXTRA\\\"} 1\"\n", 0o755)
+	write("fail", "echo 'fail 1'\nexit 3\n", 0o755)
+	write("invalid", "echo 'invalid{'\n", 0o755)
+	write("slow", "sleep 10 &\nsleep 10\n", 0o755)
+	write("not_executable", "echo 'not_executable 1'\n", 0o644)
+	write(".hidden", "echo 'hidden 1'\n", 0o755)
+
+	c := newScriptCollector(dir, 500*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c.env = append(c.env, "EXTRA=value")
+	begin := time.Now()
+	c.runScripts(context.Background())
+	if d := time.Since(begin); d > 5*time.Second {
+		t.Errorf("scripts took %v, slow script and its child weren't killed on timeout", d)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectorAdapter{c})
+	want := `
+# HELP node_script_exit_code Exit code of the last run of the script, -1 if it was killed or couldn't be started.
+# TYPE node_script_exit_code gauge
+node_script_exit_code{script="fail"} 3
+node_script_exit_code{script="invalid"} 0
+node_script_exit_code{script="ok"} 0
+node_script_exit_code{script="slow"} -1
+# HELP script_env Environment of the script.
+# TYPE script_env untyped
+script_env{extra="value",home="",path="` + scriptPath + `"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "node_script_exit_code", "script_env", "fail", "invalid", "not_executable", "hidden"); err != nil {
+		t.Error(err)
+	}
+
+	c.mtx.Lock()
+	for name, r := range c.results {
+		if got := !r.lastSuccess.IsZero(); got != (name == "ok") {
+			t.Errorf("%s: last success set %v", name, got)
+		}
+	}
+	lastSuccess := c.results["ok"].lastSuccess
+	c.mtx.Unlock()
+
+	// The last success is kept when a script starts failing.
+	write("ok", "exit 1\n", 0o755)
+	c.runScripts(context.Background())
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if r := c.results["ok"]; r.exitCode != 1 || !r.lastSuccess.Equal(lastSuccess) || r.families != nil {
+		t.Errorf("unexpected result after failure: %+v", r)
+	}
+}
+
+func TestScriptCollectorFirstUpdate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ok"), []byte("#!/bin/sh\necho 'script_value 1'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	directory, interval := *scriptDirectory, *scriptInterval
+	defer func() { *scriptDirectory, *scriptInterval = directory, interval }()
+	*scriptDirectory, *scriptInterval = dir, time.Hour
+
+	// Collectors created for a single scrape, as by the collect command,
+	// serve the scripts' output of their first run.
+	c, err := NewScriptCollector(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(interface{ Close() }).Close()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectorAdapter{c})
+	want := `
+# HELP script_value Metric read from script ok
+# TYPE script_value untyped
+script_value 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "script_value"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLimitedWriter(t *testing.T) {
+	var b strings.Builder
+	w := &limitedWriter{w: &b, n: 4}
+	for _, p := range []string{"ab", "cde", "fg"} {
+		if n, err := w.Write([]byte(p)); err != nil || n != len(p) {
+			t.Fatalf("write %q: got %d, %v", p, n, err)
+		}
+	}
+	if b.String() != "abcd" || w.dropped != 3 {
+		t.Errorf("want abcd written and 3 bytes dropped, got %q and %d", b.String(), w.dropped)
+	}
+}
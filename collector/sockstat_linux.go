@@ -33,6 +33,7 @@ const (
 var pageSize = os.Getpagesize()
 
 type sockStatCollector struct {
+	paths  Paths
 	logger *slog.Logger
 }
 
@@ -42,11 +43,14 @@ func init() {
 
 // NewSockStatCollector returns a new Collector exposing socket stats.
 func NewSockStatCollector(logger *slog.Logger) (Collector, error) {
-	return &sockStatCollector{logger}, nil
+	return &sockStatCollector{
+		paths:  currentPaths(),
+		logger: logger,
+	}, nil
 }
 
 func (c *sockStatCollector) Update(ch chan<- prometheus.Metric) error {
-	fs, err := procfs.NewFS(*procPath)
+	fs, err := procfs.NewFS(c.paths.procMountPoint())
 	if err != nil {
 		return fmt.Errorf("failed to open procfs: %w", err)
 	}
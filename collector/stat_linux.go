@@ -25,8 +25,9 @@ import (
 )
 
 type statCollector struct {
-	fs     procfs.FS
-	logger *slog.Logger
+	fs      procfs.FS
+	softirq bool
+	logger  *slog.Logger
 }
 
 var statSoftirqFlag = kingpin.Flag("collector.stat.softirq", "Export softirq calls per vector").Default("false").Bool()
@@ -80,8 +81,9 @@ func NewStatCollector(logger *slog.Logger) (Collector, error) {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
 	return &statCollector{
-		fs:     fs,
-		logger: logger,
+		fs:      fs,
+		softirq: *statSoftirqFlag,
+		logger:  logger,
 	}, nil
 }
 
@@ -101,7 +103,7 @@ func (c *statCollector) Update(ch chan<- prometheus.Metric) error {
 	ch <- prometheus.MustNewConstMetric(statProcsRunning, prometheus.GaugeValue, float64(stats.ProcessesRunning))
 	ch <- prometheus.MustNewConstMetric(statProcsBlocked, prometheus.GaugeValue, float64(stats.ProcessesBlocked))
 
-	if *statSoftirqFlag {
+	if c.softirq {
 		si := stats.SoftIRQ
 
 		for _, vec := range []struct {
@@ -0,0 +1,140 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status describes a registered collector and its last run, for diagnostics.
+type Status struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Forced is whether the collector was enabled or disabled explicitly
+	// on the command line.
+	Forced bool `json:"forced"`
+	// Options are the effective values of the collector's flags, named
+	// without the "collector.<name>." prefix. Only empty, boolean and
+	// numeric values are shown, see maskOption.
+	Options map[string][]string `json:"options"`
+	// RequestOptions are the options which may be set per request.
+	RequestOptions         []string `json:"request_options,omitempty"`
+	ScrapeTimeoutSeconds   float64  `json:"scrape_timeout_seconds,omitempty"`
+	SeriesLimit            int      `json:"series_limit,omitempty"`
+	CollectIntervalSeconds float64  `json:"collect_interval_seconds,omitempty"`
+	LastRun                *Run     `json:"last_run,omitempty"`
+	// LastError is the last failed run, which may be older than LastRun.
+	LastError *Run `json:"last_error,omitempty"`
+}
+
+// Run is the outcome of a collector update during a scrape.
+type Run struct {
+	Time            time.Time `json:"time"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Success         bool      `json:"success"`
+	TimedOut        bool      `json:"timed_out,omitempty"`
+	NoData          bool      `json:"no_data,omitempty"`
+	Series          int       `json:"series"`
+	Truncated       bool      `json:"truncated,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// maskOption hides the values of an option which aren't empty, booleans or
+// numbers. /collectors is served without authentication, and the other
+// values may hold credentials, e.g. script.env or the URL of supervisord.
+func maskOption(values []string) []string {
+	masked := slices.Clone(values)
+	for i, v := range masked {
+		if _, err := strconv.ParseBool(v); err == nil || v == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			continue
+		}
+		masked[i] = "<hidden>"
+	}
+	return masked
+}
+
+var (
+	runsMtx sync.Mutex
+	// lastRuns and lastErrors hold the last runs of the shared collectors by
+	// name, see recordRun.
+	lastRuns   = make(map[string]Run)
+	lastErrors = make(map[string]Run)
+)
+
+// recordRun records the run of the named collector if c is its instance
+// shared by the scrapes without options. The instances created for a single
+// request, profile or target run with settings of their own, so their runs
+// would mix with the shared one's.
+func recordRun(name string, c Collector, r Run) {
+	initiatedCollectorsMtx.Lock()
+	shared := initiatedCollectors[name] == c
+	initiatedCollectorsMtx.Unlock()
+	if !shared {
+		return
+	}
+
+	runsMtx.Lock()
+	defer runsMtx.Unlock()
+	lastRuns[name] = r
+	if !r.Success {
+		lastErrors[name] = r
+	}
+}
+
+// Statuses returns the status of all registered collectors, sorted by name.
+func Statuses() []Status {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	runsMtx.Lock()
+	defer runsMtx.Unlock()
+
+	current := currentSettings()
+	statuses := make([]Status, 0, len(factories))
+	for _, name := range slices.Sorted(maps.Keys(factories)) {
+		s := Status{
+			Name:                   name,
+			Enabled:                *collectorState[name],
+			Forced:                 forcedCollectors[name],
+			Options:                make(map[string][]string),
+			RequestOptions:         requestOptions[name],
+			ScrapeTimeoutSeconds:   timeoutFor(name).Seconds(),
+			SeriesLimit:            seriesLimitFor(name),
+			CollectIntervalSeconds: current.intervals[name].Seconds(),
+		}
+		prefix := "collector." + name + "."
+		for flag, values := range current.flags {
+			option, ok := strings.CutPrefix(flag, prefix)
+			if !ok {
+				continue
+			}
+			s.Options[option] = maskOption(values)
+		}
+		if r, ok := lastRuns[name]; ok {
+			s.LastRun = &r
+		}
+		if r, ok := lastErrors[name]; ok {
+			s.LastError = &r
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
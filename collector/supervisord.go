@@ -117,7 +117,22 @@ func (c *supervisordCollector) isRunning(state int) bool {
 	return false
 }
 
+// contextRoundTripper binds outgoing requests to ctx, as the xmlrpc client
+// does not accept a context itself.
+type contextRoundTripper struct {
+	ctx context.Context
+	rt  http.RoundTripper
+}
+
+func (t contextRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.rt.RoundTrip(req.WithContext(t.ctx))
+}
+
 func (c *supervisordCollector) Update(ch chan<- prometheus.Metric) error {
+	return c.UpdateContext(context.Background(), ch)
+}
+
+func (c *supervisordCollector) UpdateContext(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var info struct {
 		Name          string `xmlrpc:"name"`
 		Group         string `xmlrpc:"group"`
@@ -133,7 +148,12 @@ func (c *supervisordCollector) Update(ch chan<- prometheus.Metric) error {
 		PID           int    `xmlrpc:"pid"`
 	}
 
-	res, err := xrpc.Call("supervisor.getAllProcessInfo")
+	client := *xrpc
+	client.HttpClient = &http.Client{
+		Transport: contextRoundTripper{ctx: ctx, rt: xrpc.HttpClient.Transport},
+		Timeout:   xrpc.HttpClient.Timeout,
+	}
+	res, err := client.Call("supervisor.getAllProcessInfo")
 	if err != nil {
 		return fmt.Errorf("unable to call supervisord: %w", err)
 	}
@@ -77,13 +77,16 @@ type systemdCollector struct {
 	// Use regexps for more flexibility than device_filter.go allows
 	systemdUnitIncludePattern *regexp.Regexp
 	systemdUnitExcludePattern *regexp.Regexp
+	enableStartTimeMetrics    bool
+	enableTaskMetrics         bool
+	enableRestartsMetrics     bool
 	logger                    *slog.Logger
 }
 
 var unitStatesName = []string{"active", "activating", "deactivating", "inactive", "failed"}
 
 func init() {
-	registerCollector("systemd", defaultDisabled, NewSystemdCollector)
+	registerCollector("systemd", defaultDisabled, NewSystemdCollector, "unit-include", "unit-exclude")
 }
 
 // NewSystemdCollector returns a new Collector exposing systemd statistics.
@@ -173,21 +176,29 @@ func NewSystemdCollector(logger *slog.Logger) (Collector, error) {
 		virtualizationDesc:            virtualizationDesc,
 		systemdUnitIncludePattern:     systemdUnitIncludePattern,
 		systemdUnitExcludePattern:     systemdUnitExcludePattern,
+		enableStartTimeMetrics:        *enableStartTimeMetrics,
+		enableTaskMetrics:             *enableTaskMetrics,
+		enableRestartsMetrics:         *enableRestartsMetrics,
 		logger:                        logger,
 	}, nil
 }
 
-// Update gathers metrics from systemd.  Dbus collection is done in parallel
-// to reduce wait time for responses.
+// Update gathers metrics from systemd.
 func (c *systemdCollector) Update(ch chan<- prometheus.Metric) error {
+	return c.UpdateContext(context.Background(), ch)
+}
+
+// UpdateContext gathers metrics from systemd.  Dbus collection is done in
+// parallel to reduce wait time for responses, and stops once ctx is done.
+func (c *systemdCollector) UpdateContext(ctx context.Context, ch chan<- prometheus.Metric) error {
 	begin := time.Now()
-	conn, err := newSystemdDbusConn()
+	conn, err := newSystemdDbusConn(ctx)
 	if err != nil {
 		return fmt.Errorf("couldn't get dbus connection: %w", err)
 	}
 	defer conn.Close()
 
-	systemdVersion, systemdVersionFull := c.getSystemdVersion(conn)
+	systemdVersion, systemdVersionFull := c.getSystemdVersion(ctx, conn)
 	if systemdVersion < minSystemdVersionSystemState {
 		c.logger.Debug("Detected systemd version is lower than minimum, some systemd state and timer metrics will not be available", "current", systemdVersion, "minimum", minSystemdVersionSystemState)
 	}
@@ -198,7 +209,7 @@ func (c *systemdCollector) Update(ch chan<- prometheus.Metric) error {
 		systemdVersionFull,
 	)
 
-	systemdVirtualization := c.getSystemdVirtualization(conn)
+	systemdVirtualization := c.getSystemdVirtualization(ctx, conn)
 	ch <- prometheus.MustNewConstMetric(
 		c.virtualizationDesc,
 		prometheus.GaugeValue,
@@ -206,7 +217,7 @@ func (c *systemdCollector) Update(ch chan<- prometheus.Metric) error {
 		systemdVirtualization,
 	)
 
-	allUnits, err := c.getAllUnits(conn)
+	allUnits, err := c.getAllUnits(ctx, conn)
 	if err != nil {
 		return fmt.Errorf("couldn't get units: %w", err)
 	}
@@ -222,26 +233,24 @@ func (c *systemdCollector) Update(ch chan<- prometheus.Metric) error {
 	c.logger.Debug("filterUnits took", "duration_seconds", time.Since(begin).Seconds())
 
 	var wg sync.WaitGroup
-	defer wg.Wait()
-
 	wg.Go(func() {
 		begin := time.Now()
-		c.collectUnitStatusMetrics(conn, ch, units)
+		c.collectUnitStatusMetrics(ctx, conn, ch, units)
 		c.logger.Debug("collectUnitStatusMetrics took", "duration_seconds", time.Since(begin).Seconds())
 	})
 
-	if *enableStartTimeMetrics {
+	if c.enableStartTimeMetrics {
 		wg.Go(func() {
 			begin := time.Now()
-			c.collectUnitStartTimeMetrics(conn, ch, units)
+			c.collectUnitStartTimeMetrics(ctx, conn, ch, units)
 			c.logger.Debug("collectUnitStartTimeMetrics took", "duration_seconds", time.Since(begin).Seconds())
 		})
 	}
 
-	if *enableTaskMetrics {
+	if c.enableTaskMetrics {
 		wg.Go(func() {
 			begin := time.Now()
-			c.collectUnitTasksMetrics(conn, ch, units)
+			c.collectUnitTasksMetrics(ctx, conn, ch, units)
 			c.logger.Debug("collectUnitTasksMetrics took", "duration_seconds", time.Since(begin).Seconds())
 		})
 	}
@@ -249,38 +258,45 @@ func (c *systemdCollector) Update(ch chan<- prometheus.Metric) error {
 	if systemdVersion >= minSystemdVersionSystemState {
 		wg.Go(func() {
 			begin := time.Now()
-			c.collectTimers(conn, ch, units)
+			c.collectTimers(ctx, conn, ch, units)
 			c.logger.Debug("collectTimers took", "duration_seconds", time.Since(begin).Seconds())
 		})
 	}
 
 	wg.Go(func() {
 		begin := time.Now()
-		c.collectSockets(conn, ch, units)
+		c.collectSockets(ctx, conn, ch, units)
 		c.logger.Debug("collectSockets took", "duration_seconds", time.Since(begin).Seconds())
 	})
 
 	if systemdVersion >= minSystemdVersionSystemState {
 		begin := time.Now()
-		err = c.collectSystemState(conn, ch)
+		err = c.collectSystemState(ctx, conn, ch)
 		c.logger.Debug("collectSystemState took", "duration_seconds", time.Since(begin).Seconds())
 	}
 
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	return err
 }
 
-func (c *systemdCollector) collectUnitStatusMetrics(conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
+func (c *systemdCollector) collectUnitStatusMetrics(ctx context.Context, conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
 	for _, unit := range units {
+		if ctx.Err() != nil {
+			return
+		}
 		serviceType := ""
 		if strings.HasSuffix(unit.Name, ".service") {
-			serviceTypeProperty, err := conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Service", "Type")
+			serviceTypeProperty, err := conn.GetUnitTypePropertyContext(ctx, unit.Name, "Service", "Type")
 			if err != nil {
 				c.logger.Debug("couldn't get unit type", "unit", unit.Name, "err", err)
 			} else {
 				serviceType = serviceTypeProperty.Value.Value().(string)
 			}
 		} else if strings.HasSuffix(unit.Name, ".mount") {
-			serviceTypeProperty, err := conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Mount", "Type")
+			serviceTypeProperty, err := conn.GetUnitTypePropertyContext(ctx, unit.Name, "Mount", "Type")
 			if err != nil {
 				c.logger.Debug("couldn't get unit type", "unit", unit.Name, "err", err)
 			} else {
@@ -296,9 +312,9 @@ func (c *systemdCollector) collectUnitStatusMetrics(conn *dbus.Conn, ch chan<- p
 				c.unitDesc, prometheus.GaugeValue, isActive,
 				unit.Name, stateName, serviceType)
 		}
-		if *enableRestartsMetrics && strings.HasSuffix(unit.Name, ".service") {
+		if c.enableRestartsMetrics && strings.HasSuffix(unit.Name, ".service") {
 			// NRestarts wasn't added until systemd 235.
-			restartsCount, err := conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Service", "NRestarts")
+			restartsCount, err := conn.GetUnitTypePropertyContext(ctx, unit.Name, "Service", "NRestarts")
 			if err != nil {
 				c.logger.Debug("couldn't get unit NRestarts", "unit", unit.Name, "err", err)
 			} else {
@@ -310,13 +326,16 @@ func (c *systemdCollector) collectUnitStatusMetrics(conn *dbus.Conn, ch chan<- p
 	}
 }
 
-func (c *systemdCollector) collectSockets(conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
+func (c *systemdCollector) collectSockets(ctx context.Context, conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
 	for _, unit := range units {
+		if ctx.Err() != nil {
+			return
+		}
 		if !strings.HasSuffix(unit.Name, ".socket") {
 			continue
 		}
 
-		acceptedConnectionCount, err := conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Socket", "NAccepted")
+		acceptedConnectionCount, err := conn.GetUnitTypePropertyContext(ctx, unit.Name, "Socket", "NAccepted")
 		if err != nil {
 			c.logger.Debug("couldn't get unit NAccepted", "unit", unit.Name, "err", err)
 			continue
@@ -325,7 +344,7 @@ func (c *systemdCollector) collectSockets(conn *dbus.Conn, ch chan<- prometheus.
 			c.socketAcceptedConnectionsDesc, prometheus.CounterValue,
 			float64(acceptedConnectionCount.Value.Value().(uint32)), unit.Name)
 
-		currentConnectionCount, err := conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Socket", "NConnections")
+		currentConnectionCount, err := conn.GetUnitTypePropertyContext(ctx, unit.Name, "Socket", "NConnections")
 		if err != nil {
 			c.logger.Debug("couldn't get unit NConnections", "unit", unit.Name, "err", err)
 			continue
@@ -335,7 +354,7 @@ func (c *systemdCollector) collectSockets(conn *dbus.Conn, ch chan<- prometheus.
 			float64(currentConnectionCount.Value.Value().(uint32)), unit.Name)
 
 		// NRefused wasn't added until systemd 239.
-		refusedConnectionCount, err := conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Socket", "NRefused")
+		refusedConnectionCount, err := conn.GetUnitTypePropertyContext(ctx, unit.Name, "Socket", "NRefused")
 		if err == nil {
 			ch <- prometheus.MustNewConstMetric(
 				c.socketRefusedConnectionsDesc, prometheus.GaugeValue,
@@ -344,14 +363,17 @@ func (c *systemdCollector) collectSockets(conn *dbus.Conn, ch chan<- prometheus.
 	}
 }
 
-func (c *systemdCollector) collectUnitStartTimeMetrics(conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
+func (c *systemdCollector) collectUnitStartTimeMetrics(ctx context.Context, conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
 	var startTimeUsec uint64
 
 	for _, unit := range units {
+		if ctx.Err() != nil {
+			return
+		}
 		if unit.ActiveState != "active" {
 			startTimeUsec = 0
 		} else {
-			timestampValue, err := conn.GetUnitPropertyContext(context.TODO(), unit.Name, "ActiveEnterTimestamp")
+			timestampValue, err := conn.GetUnitPropertyContext(ctx, unit.Name, "ActiveEnterTimestamp")
 			if err != nil {
 				c.logger.Debug("couldn't get unit StartTimeUsec", "unit", unit.Name, "err", err)
 				continue
@@ -365,11 +387,14 @@ func (c *systemdCollector) collectUnitStartTimeMetrics(conn *dbus.Conn, ch chan<
 	}
 }
 
-func (c *systemdCollector) collectUnitTasksMetrics(conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
+func (c *systemdCollector) collectUnitTasksMetrics(ctx context.Context, conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
 	var val uint64
 	for _, unit := range units {
+		if ctx.Err() != nil {
+			return
+		}
 		if strings.HasSuffix(unit.Name, ".service") {
-			tasksCurrentCount, err := conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Service", "TasksCurrent")
+			tasksCurrentCount, err := conn.GetUnitTypePropertyContext(ctx, unit.Name, "Service", "TasksCurrent")
 			if err != nil {
 				c.logger.Debug("couldn't get unit TasksCurrent", "unit", unit.Name, "err", err)
 			} else {
@@ -381,7 +406,7 @@ func (c *systemdCollector) collectUnitTasksMetrics(conn *dbus.Conn, ch chan<- pr
 						float64(val), unit.Name)
 				}
 			}
-			tasksMaxCount, err := conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Service", "TasksMax")
+			tasksMaxCount, err := conn.GetUnitTypePropertyContext(ctx, unit.Name, "Service", "TasksMax")
 			if err != nil {
 				c.logger.Debug("couldn't get unit TasksMax", "unit", unit.Name, "err", err)
 			} else {
@@ -397,13 +422,16 @@ func (c *systemdCollector) collectUnitTasksMetrics(conn *dbus.Conn, ch chan<- pr
 	}
 }
 
-func (c *systemdCollector) collectTimers(conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
+func (c *systemdCollector) collectTimers(ctx context.Context, conn *dbus.Conn, ch chan<- prometheus.Metric, units []unit) {
 	for _, unit := range units {
+		if ctx.Err() != nil {
+			return
+		}
 		if !strings.HasSuffix(unit.Name, ".timer") {
 			continue
 		}
 
-		lastTriggerValue, err := conn.GetUnitTypePropertyContext(context.TODO(), unit.Name, "Timer", "LastTriggerUSec")
+		lastTriggerValue, err := conn.GetUnitTypePropertyContext(ctx, unit.Name, "Timer", "LastTriggerUSec")
 		if err != nil {
 			c.logger.Debug("couldn't get unit LastTriggerUSec", "unit", unit.Name, "err", err)
 			continue
@@ -422,8 +450,8 @@ func (c *systemdCollector) collectSummaryMetrics(ch chan<- prometheus.Metric, su
 	}
 }
 
-func (c *systemdCollector) collectSystemState(conn *dbus.Conn, ch chan<- prometheus.Metric) error {
-	systemState, err := conn.GetManagerProperty("SystemState")
+func (c *systemdCollector) collectSystemState(ctx context.Context, conn *dbus.Conn, ch chan<- prometheus.Metric) error {
+	systemState, err := getManagerProperty(ctx, conn, "SystemState")
 	if err != nil {
 		return fmt.Errorf("couldn't get system state: %w", err)
 	}
@@ -435,19 +463,40 @@ func (c *systemdCollector) collectSystemState(conn *dbus.Conn, ch chan<- prometh
 	return nil
 }
 
-func newSystemdDbusConn() (*dbus.Conn, error) {
+func newSystemdDbusConn(ctx context.Context) (*dbus.Conn, error) {
 	if *systemdPrivate {
-		return dbus.NewSystemdConnectionContext(context.TODO())
+		return dbus.NewSystemdConnectionContext(ctx)
+	}
+	return dbus.NewWithContext(ctx)
+}
+
+// getManagerProperty returns a property of the systemd manager like
+// conn.GetManagerProperty, which takes no context, but stops waiting for it
+// once ctx is done. The call then pending fails once conn is closed.
+func getManagerProperty(ctx context.Context, conn *dbus.Conn, prop string) (string, error) {
+	type result struct {
+		value string
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := conn.GetManagerProperty(prop)
+		done <- result{value, err}
+	}()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
 	}
-	return dbus.NewWithContext(context.TODO())
 }
 
 type unit struct {
 	dbus.UnitStatus
 }
 
-func (c *systemdCollector) getAllUnits(conn *dbus.Conn) ([]unit, error) {
-	allUnits, err := conn.ListUnitsContext(context.TODO())
+func (c *systemdCollector) getAllUnits(ctx context.Context, conn *dbus.Conn) ([]unit, error) {
+	allUnits, err := conn.ListUnitsContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -491,8 +540,8 @@ func filterUnits(units []unit, includePattern, excludePattern *regexp.Regexp, lo
 	return filtered
 }
 
-func (c *systemdCollector) getSystemdVersion(conn *dbus.Conn) (float64, string) {
-	version, err := conn.GetManagerProperty("Version")
+func (c *systemdCollector) getSystemdVersion(ctx context.Context, conn *dbus.Conn) (float64, string) {
+	version, err := getManagerProperty(ctx, conn, "Version")
 	if err != nil {
 		c.logger.Debug("Unable to get systemd version property, defaulting to 0")
 		return 0, ""
@@ -508,8 +557,8 @@ func (c *systemdCollector) getSystemdVersion(conn *dbus.Conn) (float64, string)
 	return v, version
 }
 
-func (c *systemdCollector) getSystemdVirtualization(conn *dbus.Conn) string {
-	virt, err := conn.GetManagerProperty("Virtualization")
+func (c *systemdCollector) getSystemdVirtualization(ctx context.Context, conn *dbus.Conn) string {
+	virt, err := getManagerProperty(ctx, conn, "Virtualization")
 	if err != nil {
 		c.logger.Debug("Could not get Virtualization property", "err", err)
 		return "unknown"
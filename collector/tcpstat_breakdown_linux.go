@@ -0,0 +1,277 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !notcpstat
+
+package collector
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/mdlayher/netlink"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	tcpstatPortInclude  = kingpin.Flag("collector.tcpstat.port-include", "Regexp of local listening ports to break the connections down by, e.g. ^(22|443)$. No port is broken down if unset.").String()
+	tcpstatPortExclude  = kingpin.Flag("collector.tcpstat.port-exclude", "Regexp of local listening ports not to break the connections down by.").String()
+	tcpstatRemoteGroups = kingpin.Flag("collector.tcpstat.remote-group", "Count the connections to the remote addresses in CIDRs as a group, as <group>=<cidr>[,<cidr>...]. The first matching group wins. (repeatable)").PlaceHolder("<group>=<cidrs>").Strings()
+
+	// tcpRTTBuckets and tcpCwndBuckets are the buckets of the histograms of
+	// the smoothed round-trip time and the congestion window of the
+	// connections of a port.
+	tcpRTTBuckets  = []float64{.0001, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+	tcpCwndBuckets = prometheus.ExponentialBuckets(1, 2, 12)
+)
+
+// Offsets of the fields of struct tcp_info read, all of them __u32.
+// https://github.com/torvalds/linux/blob/v6.0/include/uapi/linux/tcp.h#L214
+const (
+	tcpInfoRTT          = 68
+	tcpInfoSndCwnd      = 80
+	tcpInfoTotalRetrans = 100
+)
+
+// tcpRemoteGroup is a group of remote addresses connections are counted by.
+type tcpRemoteGroup struct {
+	name     string
+	prefixes []netip.Prefix
+}
+
+func parseTCPRemoteGroups(flags []string) ([]tcpRemoteGroup, error) {
+	var groups []tcpRemoteGroup
+	for _, flag := range flags {
+		name, cidrs, ok := strings.Cut(flag, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid remote group %q, expected <group>=<cidr>[,<cidr>...]", flag)
+		}
+		group := tcpRemoteGroup{name: name}
+		for _, cidr := range strings.Split(cidrs, ",") {
+			prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid remote group %q: %w", flag, err)
+			}
+			group.prefixes = append(group.prefixes, prefix.Masked())
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// tcpBreakdowns breaks the connections down by local listening port and by
+// remote group.
+type tcpBreakdowns struct {
+	ports        bool
+	portFilter   deviceFilter
+	remoteGroups []tcpRemoteGroup
+
+	portStates   *prometheus.Desc
+	remoteStates *prometheus.Desc
+	retransmits  *prometheus.Desc
+	rtt          *prometheus.Desc
+	cwnd         *prometheus.Desc
+	sendQueue    *prometheus.Desc
+	receiveQueue *prometheus.Desc
+}
+
+func newTCPBreakdowns() (tcpBreakdowns, error) {
+	groups, err := parseTCPRemoteGroups(*tcpstatRemoteGroups)
+	if err != nil {
+		return tcpBreakdowns{}, err
+	}
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "tcp", name), help, labels, nil)
+	}
+	return tcpBreakdowns{
+		ports:        *tcpstatPortInclude != "",
+		portFilter:   newDeviceFilter(*tcpstatPortExclude, *tcpstatPortInclude),
+		remoteGroups: groups,
+
+		portStates:   desc("port_connection_states", "Number of connection states of the connections on a local listening port.", "port", "state"),
+		remoteStates: desc("remote_connection_states", "Number of connection states of the connections to a group of remote addresses.", "remote", "state"),
+		retransmits:  desc("port_connection_retransmits", "Number of segments retransmitted by the current connections on a local listening port.", "port"),
+		rtt:          desc("port_rtt_seconds", "Smoothed round-trip time of the connections on a local listening port.", "port"),
+		cwnd:         desc("port_congestion_window_segments", "Congestion window of the connections on a local listening port.", "port"),
+		sendQueue:    desc("port_send_queue_bytes", "Number of bytes not yet acknowledged by the peers of the connections on a local listening port.", "port"),
+		receiveQueue: desc("port_receive_queue_bytes", "Number of bytes not yet read by the application from the connections on a local listening port.", "port"),
+	}, nil
+}
+
+func (b *tcpBreakdowns) enabled() bool {
+	return b.ports || len(b.remoteGroups) > 0
+}
+
+// tcpPortStats are the stats of the connections on a local listening port.
+// The queues and tcp_info are those of the connections, not of the listening
+// socket.
+type tcpPortStats struct {
+	states                  map[tcpConnectionState]float64
+	retransmits             uint64
+	rtt, cwnd               *tcpHistogram
+	sendQueue, receiveQueue uint64
+}
+
+func (b *tcpBreakdowns) update(ch chan<- prometheus.Metric, msgs []netlink.Message) {
+	ports, remotes := b.parse(msgs)
+	for port, s := range ports {
+		label := strconv.Itoa(int(port))
+		for st, value := range s.states {
+			ch <- prometheus.MustNewConstMetric(b.portStates, prometheus.GaugeValue, value, label, st.String())
+		}
+		// A gauge, as the retransmits of the connections are lost when they
+		// are closed.
+		ch <- prometheus.MustNewConstMetric(b.retransmits, prometheus.GaugeValue, float64(s.retransmits), label)
+		ch <- s.rtt.metric(b.rtt, label)
+		ch <- s.cwnd.metric(b.cwnd, label)
+		ch <- prometheus.MustNewConstMetric(b.sendQueue, prometheus.GaugeValue, float64(s.sendQueue), label)
+		ch <- prometheus.MustNewConstMetric(b.receiveQueue, prometheus.GaugeValue, float64(s.receiveQueue), label)
+	}
+	for group, states := range remotes {
+		for st, value := range states {
+			ch <- prometheus.MustNewConstMetric(b.remoteStates, prometheus.GaugeValue, value, group, st.String())
+		}
+	}
+}
+
+// parse breaks the sockets down by the local listening ports passing the
+// filter and by remote group. Listening sockets aren't counted in the remote
+// groups.
+func (b *tcpBreakdowns) parse(msgs []netlink.Message) (map[uint16]*tcpPortStats, map[string]map[tcpConnectionState]float64) {
+	ports := map[uint16]*tcpPortStats{}
+	if b.ports {
+		for _, m := range msgs {
+			msg := parseInetDiagMsg(m.Data)
+			port := binary.BigEndian.Uint16(msg.ID.SourcePort[:])
+			if tcpConnectionState(msg.State) != tcpListen || ports[port] != nil || b.portFilter.ignored(strconv.Itoa(int(port))) {
+				continue
+			}
+			ports[port] = &tcpPortStats{
+				states: map[tcpConnectionState]float64{},
+				rtt:    newTCPHistogram(tcpRTTBuckets),
+				cwnd:   newTCPHistogram(tcpCwndBuckets),
+			}
+		}
+	}
+
+	remotes := map[string]map[tcpConnectionState]float64{}
+	for _, m := range msgs {
+		msg := parseInetDiagMsg(m.Data)
+		state := tcpConnectionState(msg.State)
+		if s, ok := ports[binary.BigEndian.Uint16(msg.ID.SourcePort[:])]; ok {
+			s.states[state]++
+			if state != tcpListen {
+				s.sendQueue += uint64(msg.WQueue)
+				s.receiveQueue += uint64(msg.RQueue)
+				if info := tcpInfo(m.Data); info != nil {
+					s.rtt.observe(float64(binary.NativeEndian.Uint32(info[tcpInfoRTT:])) / 1e6)
+					s.cwnd.observe(float64(binary.NativeEndian.Uint32(info[tcpInfoSndCwnd:])))
+					s.retransmits += uint64(binary.NativeEndian.Uint32(info[tcpInfoTotalRetrans:]))
+				}
+			}
+		}
+		if state == tcpListen || len(b.remoteGroups) == 0 {
+			continue
+		}
+		remote := msg.ID.destAddr(msg.Family)
+		for _, group := range b.remoteGroups {
+			if !containsAddr(group.prefixes, remote) {
+				continue
+			}
+			if remotes[group.name] == nil {
+				remotes[group.name] = map[tcpConnectionState]float64{}
+			}
+			remotes[group.name][state]++
+			break
+		}
+	}
+	return ports, remotes
+}
+
+// tcpInfo returns the tcp_info attribute following the inet_diag_msg, or nil
+// if the socket has none, as time-wait sockets, or the kernel's is too old.
+func tcpInfo(b []byte) []byte {
+	if len(b) <= sizeOfDiagMsg {
+		return nil
+	}
+	ad, err := netlink.NewAttributeDecoder(b[sizeOfDiagMsg:])
+	if err != nil {
+		return nil
+	}
+	for ad.Next() {
+		if ad.Type() == inetDiagInfo {
+			if info := ad.Bytes(); len(info) >= tcpInfoTotalRetrans+4 {
+				return info
+			}
+		}
+	}
+	return nil
+}
+
+// destAddr returns the remote address of the socket, IPv4-mapped IPv6
+// addresses being unmapped.
+func (id InetDiagSockID) destAddr(family uint8) netip.Addr {
+	if family == syscall.AF_INET {
+		return netip.AddrFrom4(id.DestIP[0])
+	}
+	var addr [16]byte
+	for i, part := range id.DestIP {
+		copy(addr[4*i:], part[:])
+	}
+	return netip.AddrFrom16(addr).Unmap()
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// tcpHistogram is a histogram of a value of the connections of a port.
+type tcpHistogram struct {
+	buckets []float64
+	// counts are cumulative, like the ones of a const histogram.
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newTCPHistogram(buckets []float64) *tcpHistogram {
+	return &tcpHistogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *tcpHistogram) observe(v float64) {
+	h.count++
+	h.sum += v
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+}
+
+func (h *tcpHistogram) metric(desc *prometheus.Desc, labels ...string) prometheus.Metric {
+	buckets := make(map[float64]uint64, len(h.buckets))
+	for i, upper := range h.buckets {
+		buckets[upper] = h.counts[i]
+	}
+	return prometheus.MustNewConstHistogram(desc, h.count, h.sum, buckets, labels...)
+}
@@ -58,23 +58,31 @@ const (
 )
 
 type tcpStatCollector struct {
-	desc   typedDesc
-	logger *slog.Logger
+	desc       typedDesc
+	breakdowns tcpBreakdowns
+	paths      Paths
+	logger     *slog.Logger
 }
 
 func init() {
-	registerCollector("tcpstat", defaultDisabled, NewTCPStatCollector)
+	registerCollector("tcpstat", defaultDisabled, NewTCPStatCollector, "port-include", "port-exclude")
 }
 
 // NewTCPStatCollector returns a new Collector exposing network stats.
 func NewTCPStatCollector(logger *slog.Logger) (Collector, error) {
+	breakdowns, err := newTCPBreakdowns()
+	if err != nil {
+		return nil, err
+	}
 	return &tcpStatCollector{
 		desc: typedDesc{prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "tcp", "connection_states"),
 			"Number of connection states.",
 			[]string{"state"}, nil,
 		), prometheus.GaugeValue},
-		logger: logger,
+		breakdowns: breakdowns,
+		paths:      currentPaths(),
+		logger:     logger,
 	}, nil
 }
 
@@ -123,38 +131,49 @@ type InetDiagMsg struct {
 	Inode   uint32
 }
 
+// sizeOfDiagMsg is the size of InetDiagMsg, the attributes following it.
+const sizeOfDiagMsg = 0x48
+
+// inetDiagInfo (INET_DIAG_INFO) is the attribute holding the tcp_info of a
+// socket.
+const inetDiagInfo = 2
+
 func parseInetDiagMsg(b []byte) *InetDiagMsg {
 	return (*InetDiagMsg)(unsafe.Pointer(&b[0]))
 }
 
 func (c *tcpStatCollector) Update(ch chan<- prometheus.Metric) error {
-	tcpStats, err := getTCPStats(syscall.AF_INET)
+	msgs, err := getTCPSockets(syscall.AF_INET)
 	if err != nil {
 		return fmt.Errorf("couldn't get tcpstats: %w", err)
 	}
 
 	// if enabled ipv6 system
-	if _, hasIPv6 := os.Stat(procFilePath("net/tcp6")); hasIPv6 == nil {
-		tcp6Stats, err := getTCPStats(syscall.AF_INET6)
+	if _, hasIPv6 := os.Stat(c.paths.procFilePath("net/tcp6")); hasIPv6 == nil {
+		msgs6, err := getTCPSockets(syscall.AF_INET6)
 		if err != nil {
 			return fmt.Errorf("couldn't get tcp6stats: %w", err)
 		}
-
-		for st, value := range tcp6Stats {
-			tcpStats[st] += value
-		}
+		msgs = append(msgs, msgs6...)
 	}
 
+	tcpStats, err := parseTCPStats(msgs)
+	if err != nil {
+		return err
+	}
 	for st, value := range tcpStats {
 		ch <- c.desc.mustNewConstMetric(value, st.String())
 	}
 
+	if c.breakdowns.enabled() {
+		c.breakdowns.update(ch, msgs)
+	}
 	return nil
 }
 
-func getTCPStats(family uint8) (map[tcpConnectionState]float64, error) {
+// getTCPSockets dumps the TCP sockets of family with their tcp_info.
+func getTCPSockets(family uint8) ([]netlink.Message, error) {
 	const TCPFAll = 0xFFF
-	const InetDiagInfo = 2
 	const SockDiagByFamily = 20
 
 	conn, err := netlink.Dial(syscall.NETLINK_INET_DIAG, nil)
@@ -172,16 +191,11 @@ func getTCPStats(family uint8) (map[tcpConnectionState]float64, error) {
 			Family:   family,
 			Protocol: syscall.IPPROTO_TCP,
 			States:   TCPFAll,
-			Ext:      0 | 1<<(InetDiagInfo-1),
+			Ext:      0 | 1<<(inetDiagInfo-1),
 		}).Serialize(),
 	}
 
-	messages, err := conn.Execute(msg)
-	if err != nil {
-		return nil, err
-	}
-
-	return parseTCPStats(messages)
+	return conn.Execute(msg)
 }
 
 func parseTCPStats(msgs []netlink.Message) (map[tcpConnectionState]float64, error) {
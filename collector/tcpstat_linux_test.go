@@ -18,6 +18,9 @@ package collector
 import (
 	"bytes"
 	"encoding/binary"
+	"maps"
+	"math"
+	"net/netip"
 	"syscall"
 	"testing"
 
@@ -86,3 +89,80 @@ func Test_parseTCPStats(t *testing.T) {
 	}
 
 }
+
+func Test_parseTCPBreakdowns(t *testing.T) {
+	sock := func(family uint8, state tcpConnectionState, port uint16, remote netip.Addr, rtt, cwnd, retrans uint32) netlink.Message {
+		m := InetDiagMsg{Family: family, State: uint8(state), RQueue: 3, WQueue: 5}
+		binary.BigEndian.PutUint16(m.ID.SourcePort[:], port)
+		if remote.Is4() {
+			m.ID.DestIP[0] = remote.As4()
+		} else {
+			addr := remote.As16()
+			for i := range m.ID.DestIP {
+				copy(m.ID.DestIP[i][:], addr[4*i:])
+			}
+		}
+		var buf bytes.Buffer
+		if err := binary.Write(&buf, binary.NativeEndian, m); err != nil {
+			t.Fatal(err)
+		}
+		info := make([]byte, 104)
+		binary.NativeEndian.PutUint32(info[tcpInfoRTT:], rtt)
+		binary.NativeEndian.PutUint32(info[tcpInfoSndCwnd:], cwnd)
+		binary.NativeEndian.PutUint32(info[tcpInfoTotalRetrans:], retrans)
+		attrs, err := netlink.MarshalAttributes([]netlink.Attribute{{Type: inetDiagInfo, Data: info}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return netlink.Message{Data: append(buf.Bytes(), attrs...)}
+	}
+	any6 := netip.IPv6Unspecified()
+	msgs := []netlink.Message{
+		sock(syscall.AF_INET6, tcpListen, 22, any6, 0, 10, 0),
+		sock(syscall.AF_INET6, tcpListen, 8080, any6, 0, 10, 0),
+		sock(syscall.AF_INET6, tcpEstablished, 22, netip.MustParseAddr("::ffff:10.0.0.1"), 2000, 10, 1),
+		sock(syscall.AF_INET, tcpEstablished, 22, netip.MustParseAddr("192.168.1.2"), 30000, 40, 2),
+		sock(syscall.AF_INET, tcpEstablished, 8080, netip.MustParseAddr("10.0.0.2"), 0, 10, 7),
+		sock(syscall.AF_INET, tcpSynSent, 41000, netip.MustParseAddr("10.1.0.1"), 0, 1, 0),
+	}
+
+	groups, err := parseTCPRemoteGroups([]string{"internal=10.0.0.0/16", "private=10.0.0.0/8, 192.168.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := tcpBreakdowns{
+		ports:        true,
+		portFilter:   newDeviceFilter("^8080$", ".+"),
+		remoteGroups: groups,
+	}
+	ports, remotes := b.parse(msgs)
+
+	if len(ports) != 1 || ports[22] == nil {
+		t.Fatalf("want port 22 broken down only, got %v", ports)
+	}
+	s := ports[22]
+	if want, got := (map[tcpConnectionState]float64{tcpListen: 1, tcpEstablished: 2}), s.states; !maps.Equal(want, got) {
+		t.Errorf("want port states %v, got %v", want, got)
+	}
+	if s.retransmits != 3 || s.sendQueue != 10 || s.receiveQueue != 6 {
+		t.Errorf("want 3 retransmits and queues of 10 and 6 bytes, got %d, %d and %d", s.retransmits, s.sendQueue, s.receiveQueue)
+	}
+	if s.rtt.count != 2 || math.Abs(s.rtt.sum-.032) > 1e-9 || s.rtt.counts[2] != 0 || s.rtt.counts[3] != 1 || s.rtt.counts[7] != 2 {
+		t.Errorf("unexpected RTT histogram %+v", s.rtt)
+	}
+	if s.cwnd.sum != 50 || s.cwnd.counts[3] != 0 || s.cwnd.counts[4] != 1 || s.cwnd.counts[6] != 2 {
+		t.Errorf("unexpected congestion window histogram %+v", s.cwnd)
+	}
+
+	want := map[string]map[tcpConnectionState]float64{
+		"internal": {tcpEstablished: 2},
+		"private":  {tcpEstablished: 1, tcpSynSent: 1},
+	}
+	if !maps.EqualFunc(want, remotes, maps.Equal) {
+		t.Errorf("want remote states %v, got %v", want, remotes)
+	}
+
+	if _, err := parseTCPRemoteGroups([]string{"bad=10.0.0.0"}); err == nil {
+		t.Error("want an error for a remote group without a prefix length")
+	}
+}
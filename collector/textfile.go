@@ -16,14 +16,18 @@
 package collector
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
@@ -33,22 +37,48 @@ import (
 )
 
 var (
-	textFileDirectories = kingpin.Flag("collector.textfile.directory", "Directory to read text files with metrics from, supports glob matching. (repeatable)").Default("").Strings()
-	mtimeDesc           = prometheus.NewDesc(
+	textFileDirectories     = kingpin.Flag("collector.textfile.directory", "Directory to read text files with metrics from, supports glob matching. (repeatable)").Default("").Strings()
+	textFileHonorTimestamps = kingpin.Flag("collector.textfile.honor-timestamps", "Keep the timestamps of samples in text files instead of rejecting files having them.").Default("false").Bool()
+	textFileMaxAge          = kingpin.Flag("collector.textfile.max-age", "Maximum age of text files, by modification time. Older files are dropped from the output. 0 disables the limit.").Default("0s").Duration()
+	textFileWatch           = kingpin.Flag("collector.textfile.watch", "Watch the textfile directories with inotify and only parse the files which changed since the last scrape (Linux only).").Default("false").Bool()
+	textFileMaxAgeOverrides = kingpin.Flag("collector.textfile.path-max-age", "Maximum age of the text files matching a path, overriding --collector.textfile.max-age, as <path>=<duration>. The path is matched against the file, then its directory, and supports glob matching. (repeatable)").PlaceHolder("<path>=<duration>").Strings()
+	mtimeDesc               = prometheus.NewDesc(
 		"node_textfile_mtime_seconds",
 		"Unixtime mtime of textfiles successfully read.",
 		[]string{"file"},
 		nil,
 	)
+	staleFilesDesc = prometheus.NewDesc(
+		"node_textfile_stale_files",
+		"Number of textfiles dropped because they are older than their maximum age.",
+		nil, nil,
+	)
 )
 
+// errStaleFile is returned for files older than their maximum age.
+var errStaleFile = errors.New("file exceeds its maximum age")
+
 type textFileCollector struct {
-	paths []string
+	paths           []string
+	honorTimestamps bool
+	maxAge          time.Duration
+	maxAgeOverrides []pathValue[time.Duration]
+	policies        textFilePolicies
+	// watcher caches the directory listings and parsed files, nil unless
+	// watching is enabled.
+	watcher *textFileWatcher
 	// Only set for testing to get predictable output.
 	mtime  *float64
 	logger *slog.Logger
 }
 
+// textFileResult is the outcome of processing a file.
+type textFileResult struct {
+	mtime    *time.Time
+	families map[string]*dto.MetricFamily
+	err      error
+}
+
 func init() {
 	registerCollector("textfile", defaultEnabled, NewTextFileCollector)
 }
@@ -57,15 +87,86 @@ func init() {
 // in the given textfile directory.
 func NewTextFileCollector(logger *slog.Logger) (Collector, error) {
 	c := &textFileCollector{
-		paths:  *textFileDirectories,
-		logger: logger,
+		paths:           *textFileDirectories,
+		honorTimestamps: *textFileHonorTimestamps,
+		maxAge:          *textFileMaxAge,
+		logger:          logger,
+	}
+	var err error
+	if c.maxAgeOverrides, err = parsePathValues(*textFileMaxAgeOverrides, time.ParseDuration); err != nil {
+		return nil, fmt.Errorf("maximum age: %w", err)
+	}
+	if c.policies, err = newTextFilePolicies(); err != nil {
+		return nil, err
+	}
+	if *textFileWatch {
+		w, err := newTextFileWatcher()
+		if err != nil {
+			return nil, err
+		}
+		c.watcher = w
 	}
 	return c, nil
 }
 
-func convertMetricFamily(metricFamily *dto.MetricFamily, ch chan<- prometheus.Metric, logger *slog.Logger) {
+// Close stops watching the textfile directories.
+func (c *textFileCollector) Close() {
+	if c.watcher != nil {
+		c.watcher.Close()
+	}
+}
+
+func (c *textFileCollector) readDir(path string) ([]os.DirEntry, error) {
+	if c.watcher == nil {
+		return os.ReadDir(path)
+	}
+	return c.watcher.readDir(path)
+}
+
+// cachedProcessFile is processFile, using the results cached by the watcher
+// for files which didn't change.
+func (c *textFileCollector) cachedProcessFile(dir, name string) (*time.Time, map[string]*dto.MetricFamily, error) {
+	if c.watcher == nil {
+		return c.processFile(dir, name)
+	}
+	r := c.watcher.processFile(dir, name, func(dir, name string) *textFileResult {
+		mtime, families, err := c.processFile(dir, name)
+		return &textFileResult{mtime: mtime, families: families, err: err}
+	})
+	// A file may have become stale since it was processed.
+	if r.err == nil {
+		if err := c.checkAge(dir, filepath.Join(dir, name), *r.mtime); err != nil {
+			return nil, nil, err
+		}
+	}
+	return r.mtime, r.families, r.err
+}
+
+// maxAgeFor returns the maximum age of the file at path in dir, 0 if it has
+// none.
+func (c *textFileCollector) maxAgeFor(dir, path string) time.Duration {
+	if maxAge, ok := matchPath(c.maxAgeOverrides, dir, path); ok {
+		return maxAge
+	}
+	return c.maxAge
+}
+
+// convertMetricFamily sends the metrics of metricFamily to ch. Metrics which
+// can't be created, e.g. as their labels are inconsistent, are skipped and
+// their errors returned.
+func convertMetricFamily(metricFamily *dto.MetricFamily, ch chan<- prometheus.Metric, honorTimestamps bool, logger *slog.Logger) error {
 	var valType prometheus.ValueType
 	var val float64
+	var errs []error
+
+	// valid passes on the metrics which could be created and records the
+	// errors of the others.
+	valid := func(m prometheus.Metric, err error) prometheus.Metric {
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return m
+	}
 
 	allLabelNames := map[string]struct{}{}
 	for _, metric := range metricFamily.Metric {
@@ -78,9 +179,26 @@ func convertMetricFamily(metricFamily *dto.MetricFamily, ch chan<- prometheus.Me
 	}
 
 	for _, metric := range metricFamily.Metric {
-		if metric.TimestampMs != nil {
+		if metric.TimestampMs != nil && !honorTimestamps {
 			logger.Warn("Ignoring unsupported custom timestamp on textfile collector metric", "metric", metric)
 		}
+		send := func(m prometheus.Metric, exemplars ...*dto.Exemplar) {
+			if m == nil {
+				return
+			}
+			if metric.TimestampMs != nil && honorTimestamps {
+				m = prometheus.NewMetricWithTimestamp(time.UnixMilli(metric.GetTimestampMs()), m)
+			}
+			if len(exemplars) > 0 {
+				withExemplars, err := prometheus.NewMetricWithExemplars(m, convertExemplars(exemplars)...)
+				if err != nil {
+					logger.Warn("Ignoring invalid exemplars on textfile collector metric", "metric", metricFamily.GetName(), "err", err)
+				} else {
+					m = withExemplars
+				}
+			}
+			ch <- m
+		}
 
 		labels := metric.GetLabel()
 		var names []string
@@ -97,11 +215,24 @@ func convertMetricFamily(metricFamily *dto.MetricFamily, ch chan<- prometheus.Me
 			}
 		}
 
+		desc := prometheus.NewDesc(
+			*metricFamily.Name,
+			metricFamily.GetHelp(),
+			names, nil,
+		)
 		metricType := metricFamily.GetType()
 		switch metricType {
 		case dto.MetricType_COUNTER:
 			valType = prometheus.CounterValue
 			val = metric.Counter.GetValue()
+			if ct := metric.Counter.GetCreatedTimestamp(); ct != nil {
+				var exemplars []*dto.Exemplar
+				if e := metric.Counter.GetExemplar(); e != nil {
+					exemplars = append(exemplars, e)
+				}
+				send(valid(prometheus.NewConstMetricWithCreatedTimestamp(desc, valType, val, ct.AsTime(), values...)), exemplars...)
+				continue
+			}
 
 		case dto.MetricType_GAUGE:
 			valType = prometheus.GaugeValue
@@ -116,45 +247,112 @@ func convertMetricFamily(metricFamily *dto.MetricFamily, ch chan<- prometheus.Me
 			for _, q := range metric.Summary.Quantile {
 				quantiles[q.GetQuantile()] = q.GetValue()
 			}
-			ch <- prometheus.MustNewConstSummary(
-				prometheus.NewDesc(
-					*metricFamily.Name,
-					metricFamily.GetHelp(),
-					names, nil,
-				),
+			if ct := metric.Summary.GetCreatedTimestamp(); ct != nil {
+				send(valid(prometheus.NewConstSummaryWithCreatedTimestamp(
+					desc,
+					metric.Summary.GetSampleCount(),
+					metric.Summary.GetSampleSum(),
+					quantiles, ct.AsTime(), values...,
+				)))
+				continue
+			}
+			send(valid(prometheus.NewConstSummary(
+				desc,
 				metric.Summary.GetSampleCount(),
 				metric.Summary.GetSampleSum(),
 				quantiles, values...,
-			)
+			)))
 		case dto.MetricType_HISTOGRAM:
+			if metric.Histogram.Schema != nil {
+				// Native histograms, and the exemplars they hold, are
+				// passed through as parsed.
+				send(nativeHistogram{
+					desc:      desc,
+					histogram: metric.Histogram,
+					labels:    prometheus.MakeLabelPairs(desc, values),
+				})
+				continue
+			}
 			buckets := map[float64]uint64{}
+			var exemplars []*dto.Exemplar
 			for _, b := range metric.Histogram.Bucket {
 				buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+				if b.Exemplar != nil {
+					exemplars = append(exemplars, b.Exemplar)
+				}
+			}
+			if ct := metric.Histogram.GetCreatedTimestamp(); ct != nil {
+				send(valid(prometheus.NewConstHistogramWithCreatedTimestamp(
+					desc,
+					metric.Histogram.GetSampleCount(),
+					metric.Histogram.GetSampleSum(),
+					buckets, ct.AsTime(), values...,
+				)), exemplars...)
+				continue
 			}
-			ch <- prometheus.MustNewConstHistogram(
-				prometheus.NewDesc(
-					*metricFamily.Name,
-					metricFamily.GetHelp(),
-					names, nil,
-				),
+			send(valid(prometheus.NewConstHistogram(
+				desc,
 				metric.Histogram.GetSampleCount(),
 				metric.Histogram.GetSampleSum(),
 				buckets, values...,
-			)
+			)), exemplars...)
+		case dto.MetricType_GAUGE_HISTOGRAM:
+			// Gauge histograms can't be exposed as such, so expose their
+			// series as gauges, like Prometheus ingests them.
+			name, help := metricFamily.GetName(), metricFamily.GetHelp()
+			bucketDesc := prometheus.NewDesc(name+"_bucket", help, append(slices.Clone(names), model.BucketLabel), nil)
+			for _, b := range metric.Histogram.Bucket {
+				le := strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
+				send(valid(prometheus.NewConstMetric(bucketDesc, prometheus.GaugeValue, float64(b.GetCumulativeCount()), append(slices.Clone(values), le)...)))
+			}
+			send(valid(prometheus.NewConstMetric(prometheus.NewDesc(name+"_gcount", help, names, nil), prometheus.GaugeValue, float64(metric.Histogram.GetSampleCount()), values...)))
+			send(valid(prometheus.NewConstMetric(prometheus.NewDesc(name+"_gsum", help, names, nil), prometheus.GaugeValue, metric.Histogram.GetSampleSum(), values...)))
 		default:
-			panic("unknown metric type")
+			logger.Warn("Ignoring textfile collector metric of unsupported type", "metric", metricFamily.GetName(), "type", metricType)
+			return nil
 		}
 		if metricType == dto.MetricType_GAUGE || metricType == dto.MetricType_COUNTER || metricType == dto.MetricType_UNTYPED {
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(
-					*metricFamily.Name,
-					metricFamily.GetHelp(),
-					names, nil,
-				),
-				valType, val, values...,
-			)
+			var exemplars []*dto.Exemplar
+			if e := metric.GetCounter().GetExemplar(); e != nil {
+				exemplars = append(exemplars, e)
+			}
+			send(valid(prometheus.NewConstMetric(desc, valType, val, values...)), exemplars...)
 		}
 	}
+	return errors.Join(errs...)
+}
+
+// nativeHistogram is a native histogram read from a textfile.
+type nativeHistogram struct {
+	desc      *prometheus.Desc
+	histogram *dto.Histogram
+	labels    []*dto.LabelPair
+}
+
+func (h nativeHistogram) Desc() *prometheus.Desc {
+	return h.desc
+}
+
+func (h nativeHistogram) Write(out *dto.Metric) error {
+	out.Histogram = h.histogram
+	out.Label = h.labels
+	return nil
+}
+
+func convertExemplars(exemplars []*dto.Exemplar) []prometheus.Exemplar {
+	converted := make([]prometheus.Exemplar, 0, len(exemplars))
+	for _, e := range exemplars {
+		labels := prometheus.Labels{}
+		for _, l := range e.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		ex := prometheus.Exemplar{Value: e.GetValue(), Labels: labels}
+		if e.Timestamp != nil {
+			ex.Timestamp = e.GetTimestamp().AsTime()
+		}
+		converted = append(converted, ex)
+	}
+	return converted
 }
 
 func (c *textFileCollector) exportMTimes(mtimes map[string]time.Time, ch chan<- prometheus.Metric) {
@@ -200,8 +398,9 @@ func (c *textFileCollector) Update(ch chan<- prometheus.Metric) error {
 	}
 
 	mtimes := make(map[string]time.Time)
+	var stale int
 	for _, path := range paths {
-		files, err := os.ReadDir(path)
+		files, err := c.readDir(path)
 		if err != nil && path != "" {
 			errored = true
 			c.logger.Error("failed to read textfile collector directory", "path", path, "err", err)
@@ -209,11 +408,26 @@ func (c *textFileCollector) Update(ch chan<- prometheus.Metric) error {
 
 		for _, f := range files {
 			metricsFilePath := filepath.Join(path, f.Name())
-			if !strings.HasSuffix(f.Name(), ".prom") {
+			if !slices.Contains(textFileFormats, filepath.Ext(f.Name())) {
 				continue
 			}
 
-			mtime, families, err := c.processFile(path, f.Name())
+			mtime, families, err := c.cachedProcessFile(path, f.Name())
+			if errors.Is(err, errStaleFile) {
+				stale++
+				c.logger.Debug("skipping stale textfile", "file", metricsFilePath, "err", err)
+				continue
+			}
+			if err == nil {
+				if families, err = c.policies.apply(path, metricsFilePath, families); err != nil {
+					var policyErr *policyError
+					errors.As(err, &policyErr)
+					errored = true
+					ch <- prometheus.MustNewConstMetric(fileErrorDesc, prometheus.GaugeValue, 1, metricsFilePath, policyErr.reason)
+					c.logger.Error("textfile rejected by policy", "file", metricsFilePath, "err", err)
+					continue
+				}
+			}
 
 			for _, mf := range families {
 				// Check for metrics with inconsistent help texts and take the first help text occurrence.
@@ -248,8 +462,12 @@ func (c *textFileCollector) Update(ch chan<- prometheus.Metric) error {
 	}
 
 	mfHelp := make(map[string]*string)
-	for _, mf := range parsedFamilies {
+	for i, mf := range parsedFamilies {
 		if mf.Help == nil {
+			// Set the help text on a copy, the parsed families may be
+			// cached by the watcher.
+			mf = &dto.MetricFamily{Name: mf.Name, Type: mf.Type, Unit: mf.Unit, Metric: mf.Metric}
+			parsedFamilies[i] = mf
 			if help, ok := mfHelp[*mf.Name]; ok {
 				mf.Help = help
 				continue
@@ -261,10 +479,16 @@ func (c *textFileCollector) Update(ch chan<- prometheus.Metric) error {
 	}
 
 	for _, mf := range parsedFamilies {
-		convertMetricFamily(mf, ch, c.logger)
+		if err := convertMetricFamily(mf, ch, c.honorTimestamps, c.logger); err != nil {
+			errored = true
+			c.logger.Error("invalid textfile metrics", "metric", mf.GetName(), "files", metricsNamesToFiles[mf.GetName()], "err", err)
+		}
 	}
 
 	c.exportMTimes(mtimes, ch)
+	if c.maxAge > 0 || len(c.maxAgeOverrides) > 0 {
+		ch <- prometheus.MustNewConstMetric(staleFilesDesc, prometheus.GaugeValue, float64(stale))
+	}
 
 	// Export if there were errors.
 	var errVal float64
@@ -284,7 +508,9 @@ func (c *textFileCollector) Update(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-// processFile processes a single file, returning its modification time on success.
+// processFile processes a single file, returning its modification time on
+// success. Files older than their maximum age are not parsed and fail with
+// errStaleFile.
 func (c *textFileCollector) processFile(dir, name string) (*time.Time, map[string]*dto.MetricFamily, error) {
 	path := filepath.Join(dir, name)
 	f, err := os.Open(path)
@@ -293,13 +519,22 @@ func (c *textFileCollector) processFile(dir, name string) (*time.Time, map[strin
 	}
 	defer f.Close()
 
-	parser := expfmt.NewTextParser(model.LegacyValidation)
-	families, err := parser.TextToMetricFamilies(f)
+	if c.maxAgeFor(dir, path) > 0 {
+		stat, err := f.Stat()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to stat %q: %w", path, err)
+		}
+		if err := c.checkAge(dir, path, stat.ModTime()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	families, err := parseTextFile(f, filepath.Ext(name))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse textfile data from %q: %w", path, err)
 	}
 
-	if hasTimestamps(families) {
+	if !c.honorTimestamps && hasTimestamps(families) {
 		return nil, nil, fmt.Errorf("textfile %q contains unsupported client-side timestamps, skipping entire file", path)
 	}
 
@@ -314,6 +549,85 @@ func (c *textFileCollector) processFile(dir, name string) (*time.Time, map[strin
 	return &t, families, nil
 }
 
+// checkAge returns an errStaleFile error if the file at path in dir, last
+// modified at modTime, is older than its maximum age.
+func (c *textFileCollector) checkAge(dir, path string, modTime time.Time) error {
+	maxAge := c.maxAgeFor(dir, path)
+	if age := time.Since(modTime); maxAge > 0 && age > maxAge {
+		return fmt.Errorf("%w: %q was modified %s ago, maximum age is %s", errStaleFile, path, age.Round(time.Second), maxAge)
+	}
+	return nil
+}
+
+// textFileFormats are the file name extensions of the supported formats.
+var textFileFormats = []string{".prom", ".om", ".pb"}
+
+// parseTextFile parses a file in the format given by its extension: the
+// Prometheus text format (.prom), the OpenMetrics text format (.om), or the
+// delimited protobuf format (.pb), which is the one carrying native histograms.
+func parseTextFile(r io.Reader, ext string) (map[string]*dto.MetricFamily, error) {
+	switch ext {
+	case ".om":
+		return parseOpenMetrics(r)
+	case ".pb":
+		families := map[string]*dto.MetricFamily{}
+		decoder := expfmt.NewDecoder(r, expfmt.NewFormat(expfmt.TypeProtoDelim))
+		for {
+			mf := &dto.MetricFamily{}
+			if err := decoder.Decode(mf); errors.Is(err, io.EOF) {
+				return families, nil
+			} else if err != nil {
+				return nil, err
+			}
+			if _, ok := families[mf.GetName()]; ok {
+				return nil, fmt.Errorf("duplicate metric family %q", mf.GetName())
+			}
+			if err := checkMetricFamily(mf); err != nil {
+				return nil, err
+			}
+			families[mf.GetName()] = mf
+		}
+	default:
+		parser := expfmt.NewTextParser(model.LegacyValidation)
+		return parser.TextToMetricFamilies(r)
+	}
+}
+
+// checkMetricFamily returns an error if the name or the labels of mf are
+// invalid, as the text format parser does but the protobuf decoder doesn't.
+func checkMetricFamily(mf *dto.MetricFamily) error {
+	if !model.LegacyValidation.IsValidMetricName(mf.GetName()) {
+		return fmt.Errorf("invalid metric name %q", mf.GetName())
+	}
+	for _, m := range mf.Metric {
+		if err := checkLabels(m.Label); err != nil {
+			return fmt.Errorf("metric %q: %w", mf.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// checkLabels returns an error if a label name is invalid, reserved or
+// repeated, or a label value isn't valid UTF-8.
+func checkLabels(labels []*dto.LabelPair) error {
+	seen := make(map[string]bool, len(labels))
+	for _, lp := range labels {
+		name := lp.GetName()
+		switch {
+		case !model.LegacyValidation.IsValidLabelName(name):
+			return fmt.Errorf("invalid label name %q", name)
+		case strings.HasPrefix(name, model.ReservedLabelPrefix):
+			return fmt.Errorf("reserved label name %q", name)
+		case seen[name]:
+			return fmt.Errorf("duplicate label name %q", name)
+		case !utf8.ValidString(lp.GetValue()):
+			return fmt.Errorf("invalid UTF-8 in the value of label %q", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
 // hasTimestamps returns true when metrics contain unsupported timestamps.
 func hasTimestamps(parsedFamilies map[string]*dto.MetricFamily) bool {
 	for _, mf := range parsedFamilies {
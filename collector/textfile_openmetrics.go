@@ -0,0 +1,462 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !notextfile
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// openMetricsTypes maps the OpenMetrics metric types to the suffixes of
+// their samples.
+var openMetricsTypes = map[string][]string{
+	"counter":        {"_total", "_created"},
+	"gauge":          {""},
+	"unknown":        {""},
+	"info":           {"_info"},
+	"stateset":       {""},
+	"summary":        {"", "_sum", "_count", "_created"},
+	"histogram":      {"_bucket", "_sum", "_count", "_created"},
+	"gaugehistogram": {"_gbucket", "_gsum", "_gcount"},
+}
+
+// openMetricsFamily is a metric family being parsed.
+type openMetricsFamily struct {
+	name, typ, help, unit string
+	// metrics are keyed by their labels, in order of appearance.
+	metrics map[string]*dto.Metric
+	order   []string
+}
+
+// parseOpenMetrics parses the OpenMetrics text format. Counters are named
+// with their _total suffix and info and stateset metrics become gauges, as
+// Prometheus ingests them. Timestamps in seconds are converted to
+// milliseconds.
+func parseOpenMetrics(r io.Reader) (map[string]*dto.MetricFamily, error) {
+	var (
+		families = map[string]*dto.MetricFamily{}
+		seen     = map[string]bool{}
+		current  *openMetricsFamily
+		eof      bool
+		lineNum  int
+	)
+	finish := func() error {
+		if current == nil {
+			return nil
+		}
+		mf, err := current.metricFamily()
+		if err != nil {
+			return err
+		}
+		if _, ok := families[mf.GetName()]; ok {
+			return fmt.Errorf("duplicate metric family %q", mf.GetName())
+		}
+		families[mf.GetName()] = mf
+		current = nil
+		return nil
+	}
+	// family returns the family of a metadata line or sample, starting a new
+	// one if it isn't the current one.
+	family := func(name string) (*openMetricsFamily, error) {
+		if current != nil && current.name == name {
+			return current, nil
+		}
+		if err := finish(); err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("metric family %q is not contiguous", name)
+		}
+		seen[name] = true
+		current = &openMetricsFamily{name: name, typ: "unknown", metrics: map[string]*dto.Metric{}}
+		return current, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if eof {
+			return nil, fmt.Errorf("line %d: content after # EOF", lineNum)
+		}
+		err := func() error {
+			if line == "# EOF" {
+				eof = true
+				return nil
+			}
+			if metadata, ok := strings.CutPrefix(line, "# "); ok {
+				keyword, rest, _ := strings.Cut(metadata, " ")
+				name, value, _ := strings.Cut(rest, " ")
+				if !model.LegacyValidation.IsValidMetricName(name) {
+					return fmt.Errorf("invalid metric name %q", name)
+				}
+				f, err := family(name)
+				if err != nil {
+					return err
+				}
+				if len(f.order) > 0 {
+					return fmt.Errorf("%s of %q after its samples", keyword, name)
+				}
+				switch keyword {
+				case "TYPE":
+					if _, ok := openMetricsTypes[value]; !ok {
+						return fmt.Errorf("unknown metric type %q", value)
+					}
+					f.typ = value
+				case "HELP":
+					f.help = unescapeOpenMetrics(value)
+				case "UNIT":
+					f.unit = value
+				default:
+					return fmt.Errorf("unknown metadata %q", keyword)
+				}
+				return nil
+			}
+			return parseOpenMetricsSample(line, current, family)
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !eof {
+		return nil, errors.New("missing # EOF")
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return families, nil
+}
+
+func parseOpenMetricsSample(line string, current *openMetricsFamily, family func(string) (*openMetricsFamily, error)) error {
+	i := strings.IndexAny(line, "{ ")
+	if i < 0 {
+		return fmt.Errorf("invalid sample %q", line)
+	}
+	name, rest := line[:i], line[i:]
+	if !model.LegacyValidation.IsValidMetricName(name) {
+		return fmt.Errorf("invalid metric name %q", name)
+	}
+	var labels []*dto.LabelPair
+	if rest[0] == '{' {
+		var err error
+		if labels, rest, err = parseOpenMetricsLabels(rest[1:]); err != nil {
+			return err
+		}
+	}
+	rest, exemplar, hasExemplar := strings.Cut(rest, " # ")
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 || !strings.HasPrefix(rest, " ") {
+		return fmt.Errorf("invalid sample %q", line)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid value %q", fields[0])
+	}
+	var timestampMs *int64
+	if len(fields) == 2 {
+		ts, err := parseOpenMetricsTimestamp(fields[1])
+		if err != nil {
+			return err
+		}
+		timestampMs = proto.Int64(ts.UnixMilli())
+	}
+
+	// Find the family of the sample by the suffixes of its type.
+	f, suffix := current, ""
+	if f != nil {
+		var ok bool
+		if suffix, ok = f.suffix(name); !ok {
+			f = nil
+		}
+	}
+	if f == nil {
+		if f, err = family(name); err != nil {
+			return err
+		}
+	}
+
+	// Labels identifying a bucket, quantile or state are not part of the
+	// metric's labels.
+	var special string
+	switch {
+	case f.typ == "histogram" && suffix == "_bucket", f.typ == "gaugehistogram" && suffix == "_gbucket":
+		special = model.BucketLabel
+	case f.typ == "summary" && suffix == "":
+		special = model.QuantileLabel
+	case f.typ == "stateset":
+		special = f.name
+	}
+	var specialValue *string
+	key := make([]string, 0, len(labels))
+	for i := 0; i < len(labels); i++ {
+		if labels[i].GetName() == special && specialValue == nil {
+			specialValue = proto.String(labels[i].GetValue())
+			if f.typ != "stateset" {
+				labels = append(labels[:i], labels[i+1:]...)
+				i--
+				continue
+			}
+		}
+		key = append(key, labels[i].GetName()+"="+strconv.Quote(labels[i].GetValue()))
+	}
+	if special != "" && specialValue == nil {
+		return fmt.Errorf("sample %q of %s %q without %s label", name, f.typ, f.name, special)
+	}
+	k := strings.Join(key, ",")
+	m, ok := f.metrics[k]
+	if !ok {
+		m = &dto.Metric{Label: labels}
+		f.metrics[k] = m
+		f.order = append(f.order, k)
+	}
+	if timestampMs != nil {
+		m.TimestampMs = timestampMs
+	}
+
+	var ex *dto.Exemplar
+	if hasExemplar {
+		if ex, err = parseOpenMetricsExemplar(exemplar); err != nil {
+			return err
+		}
+	}
+	return f.add(m, suffix, specialValue, value, ex)
+}
+
+// suffix returns the suffix of a sample name belonging to the family.
+func (f *openMetricsFamily) suffix(name string) (string, bool) {
+	for _, s := range openMetricsTypes[f.typ] {
+		if name == f.name+s {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// add sets the value of a sample in m.
+func (f *openMetricsFamily) add(m *dto.Metric, suffix string, special *string, value float64, ex *dto.Exemplar) error {
+	if ex != nil && !(f.typ == "counter" && suffix == "_total") && !strings.HasSuffix(suffix, "bucket") {
+		return fmt.Errorf("exemplar on sample %q of %s", f.name+suffix, f.typ)
+	}
+	created := func() *timestamppb.Timestamp {
+		return timestamppb.New(secondsToTime(value))
+	}
+	switch f.typ {
+	case "counter":
+		if m.Counter == nil {
+			m.Counter = &dto.Counter{}
+		}
+		if suffix == "_created" {
+			m.Counter.CreatedTimestamp = created()
+			return nil
+		}
+		m.Counter.Value = proto.Float64(value)
+		m.Counter.Exemplar = ex
+	case "gauge", "info", "stateset":
+		m.Gauge = &dto.Gauge{Value: proto.Float64(value)}
+	case "unknown":
+		m.Untyped = &dto.Untyped{Value: proto.Float64(value)}
+	case "summary":
+		if m.Summary == nil {
+			m.Summary = &dto.Summary{}
+		}
+		switch suffix {
+		case "":
+			q, err := strconv.ParseFloat(*special, 64)
+			if err != nil {
+				return fmt.Errorf("invalid quantile %q", *special)
+			}
+			m.Summary.Quantile = append(m.Summary.Quantile, &dto.Quantile{Quantile: proto.Float64(q), Value: proto.Float64(value)})
+		case "_sum":
+			m.Summary.SampleSum = proto.Float64(value)
+		case "_count":
+			m.Summary.SampleCount = proto.Uint64(uint64(value))
+		case "_created":
+			m.Summary.CreatedTimestamp = created()
+		}
+	case "histogram", "gaugehistogram":
+		if m.Histogram == nil {
+			m.Histogram = &dto.Histogram{}
+		}
+		switch suffix {
+		case "_bucket", "_gbucket":
+			le, err := strconv.ParseFloat(*special, 64)
+			if err != nil {
+				return fmt.Errorf("invalid bucket %q", *special)
+			}
+			m.Histogram.Bucket = append(m.Histogram.Bucket, &dto.Bucket{
+				UpperBound:      proto.Float64(le),
+				CumulativeCount: proto.Uint64(uint64(value)),
+				Exemplar:        ex,
+			})
+		case "_sum", "_gsum":
+			m.Histogram.SampleSum = proto.Float64(value)
+		case "_count", "_gcount":
+			m.Histogram.SampleCount = proto.Uint64(uint64(value))
+		case "_created":
+			m.Histogram.CreatedTimestamp = created()
+		}
+	}
+	return nil
+}
+
+func (f *openMetricsFamily) metricFamily() (*dto.MetricFamily, error) {
+	mf := &dto.MetricFamily{Name: proto.String(f.name)}
+	if f.help != "" {
+		mf.Help = proto.String(f.help)
+	}
+	if f.unit != "" {
+		mf.Unit = proto.String(f.unit)
+	}
+	switch f.typ {
+	case "counter":
+		mf.Name = proto.String(f.name + "_total")
+		mf.Type = dto.MetricType_COUNTER.Enum()
+	case "gauge", "stateset":
+		mf.Type = dto.MetricType_GAUGE.Enum()
+	case "info":
+		mf.Name = proto.String(f.name + "_info")
+		mf.Type = dto.MetricType_GAUGE.Enum()
+	case "unknown":
+		mf.Type = dto.MetricType_UNTYPED.Enum()
+	case "summary":
+		mf.Type = dto.MetricType_SUMMARY.Enum()
+	case "histogram":
+		mf.Type = dto.MetricType_HISTOGRAM.Enum()
+	case "gaugehistogram":
+		mf.Type = dto.MetricType_GAUGE_HISTOGRAM.Enum()
+	}
+	for _, k := range f.order {
+		m := f.metrics[k]
+		if f.typ == "counter" && m.Counter.Value == nil {
+			return nil, fmt.Errorf("counter %q without _total sample", f.name)
+		}
+		mf.Metric = append(mf.Metric, m)
+	}
+	return mf, nil
+}
+
+// parseOpenMetricsLabels parses the labels following the opening brace and
+// returns them with the rest of the line.
+func parseOpenMetricsLabels(s string) ([]*dto.LabelPair, string, error) {
+	var labels []*dto.LabelPair
+	for {
+		if rest, ok := strings.CutPrefix(s, "}"); ok {
+			// Unlike the text format parser, the label names and values
+			// are checked here, as the collector can't expose them.
+			if err := checkLabels(labels); err != nil {
+				return nil, "", err
+			}
+			return labels, rest, nil
+		}
+		name, rest, ok := strings.Cut(s, `="`)
+		if !ok || !model.LegacyValidation.IsValidLabelName(name) {
+			return nil, "", fmt.Errorf("invalid label in %q", s)
+		}
+		var value strings.Builder
+		escaped := false
+		i := 0
+		for ; i < len(rest); i++ {
+			c := rest[i]
+			if escaped {
+				switch c {
+				case 'n':
+					value.WriteByte('\n')
+				case '\\', '"':
+					value.WriteByte(c)
+				default:
+					return nil, "", fmt.Errorf("invalid escape sequence in label %q", name)
+				}
+				escaped = false
+				continue
+			}
+			if c == '\\' {
+				escaped = true
+				continue
+			}
+			if c == '"' {
+				break
+			}
+			value.WriteByte(c)
+		}
+		if i == len(rest) {
+			return nil, "", fmt.Errorf("unterminated value of label %q", name)
+		}
+		labels = append(labels, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value.String())})
+		s = strings.TrimPrefix(rest[i+1:], ",")
+	}
+}
+
+// parseOpenMetricsExemplar parses an exemplar, following the " # " after a
+// sample.
+func parseOpenMetricsExemplar(s string) (*dto.Exemplar, error) {
+	rest, ok := strings.CutPrefix(s, "{")
+	if !ok {
+		return nil, fmt.Errorf("invalid exemplar %q", s)
+	}
+	labels, rest, err := parseOpenMetricsLabels(rest)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid exemplar %q", s)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exemplar value %q", fields[0])
+	}
+	ex := &dto.Exemplar{Label: labels, Value: proto.Float64(value)}
+	if len(fields) == 2 {
+		ts, err := parseOpenMetricsTimestamp(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		ex.Timestamp = timestamppb.New(ts)
+	}
+	return ex, nil
+}
+
+// parseOpenMetricsTimestamp parses a timestamp in seconds.
+func parseOpenMetricsTimestamp(s string) (time.Time, error) {
+	ts, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(ts) || math.IsInf(ts, 0) {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return secondsToTime(ts), nil
+}
+
+func secondsToTime(s float64) time.Time {
+	sec, frac := math.Modf(s)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+}
+
+func unescapeOpenMetrics(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\"`, `"`).Replace(s)
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !notextfile
+
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+const sourceFileLabel = "source_file"
+
+var (
+	textFileMetricPrefixes  = kingpin.Flag("collector.textfile.path-metric-prefixes", "Metric name prefixes allowed in the text files matching a path, as <path>=<prefix>[,<prefix>...]. Files with other metrics are rejected. (repeatable)").PlaceHolder("<path>=<prefixes>").Strings()
+	textFileForbiddenLabels = kingpin.Flag("collector.textfile.path-forbidden-labels", "Label names forbidden in the text files matching a path, as <path>=<label>[,<label>...]. Files using them are rejected. (repeatable)").PlaceHolder("<path>=<labels>").Strings()
+	textFileMaxSeries       = kingpin.Flag("collector.textfile.path-max-series", "Maximum number of series in each text file matching a path, as <path>=<count>. Files with more are rejected. (repeatable)").PlaceHolder("<path>=<count>").Strings()
+	textFileSourceLabel     = kingpin.Flag("collector.textfile.path-source-label", "Add a "+sourceFileLabel+" label with the file path to the metrics of the text files matching a path. (repeatable)").PlaceHolder("<path>").Strings()
+	fileErrorDesc           = prometheus.NewDesc(
+		"node_textfile_file_error",
+		"1 if a textfile was rejected because it breaks the policy of its directory.",
+		[]string{"file", "reason"},
+		nil,
+	)
+)
+
+// pathValue is a setting for the text files matching a path pattern. The
+// pattern is matched against the file, then its directory.
+type pathValue[T any] struct {
+	pattern string
+	value   T
+}
+
+// parsePathValues parses repeated <path>=<value> flag values.
+func parsePathValues[T any](values []string, parse func(string) (T, error)) ([]pathValue[T], error) {
+	var pvs []pathValue[T]
+	for _, v := range values {
+		pattern, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected <path>=<value>, got %q", v)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", pattern, err)
+		}
+		parsed, err := parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for path %q: %w", pattern, err)
+		}
+		pvs = append(pvs, pathValue[T]{pattern: filepath.Clean(pattern), value: parsed})
+	}
+	return pvs, nil
+}
+
+// matchPath returns the value of the first pattern matching the file at path,
+// or else its directory dir.
+func matchPath[T any](pvs []pathValue[T], dir, path string) (T, bool) {
+	for _, p := range []string{path, filepath.Clean(dir)} {
+		for _, pv := range pvs {
+			if ok, _ := filepath.Match(pv.pattern, p); ok {
+				return pv.value, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func splitList(s string) ([]string, error) {
+	return strings.Split(s, ","), nil
+}
+
+// textFilePolicies holds the policies of the text files by path pattern.
+type textFilePolicies struct {
+	metricPrefixes  []pathValue[[]string]
+	forbiddenLabels []pathValue[[]string]
+	maxSeries       []pathValue[int]
+	sourceLabel     []pathValue[bool]
+}
+
+func newTextFilePolicies() (textFilePolicies, error) {
+	var (
+		p   textFilePolicies
+		err error
+	)
+	if p.metricPrefixes, err = parsePathValues(*textFileMetricPrefixes, splitList); err != nil {
+		return p, fmt.Errorf("metric prefixes: %w", err)
+	}
+	if p.forbiddenLabels, err = parsePathValues(*textFileForbiddenLabels, splitList); err != nil {
+		return p, fmt.Errorf("forbidden labels: %w", err)
+	}
+	if p.maxSeries, err = parsePathValues(*textFileMaxSeries, strconv.Atoi); err != nil {
+		return p, fmt.Errorf("maximum series: %w", err)
+	}
+	for _, pattern := range *textFileSourceLabel {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return p, fmt.Errorf("source label: invalid path %q: %w", pattern, err)
+		}
+		p.sourceLabel = append(p.sourceLabel, pathValue[bool]{pattern: filepath.Clean(pattern), value: true})
+	}
+	return p, nil
+}
+
+// policyError is returned for files breaking their policy.
+type policyError struct {
+	reason string
+	err    error
+}
+
+func (e *policyError) Error() string {
+	return e.err.Error()
+}
+
+// apply checks the families of the file at path in dir against its policy.
+// It returns the families to expose, with the source label added if
+// configured, or a *policyError.
+func (p textFilePolicies) apply(dir, path string, families map[string]*dto.MetricFamily) (map[string]*dto.MetricFamily, error) {
+	sourceLabel, _ := matchPath(p.sourceLabel, dir, path)
+	forbidden, _ := matchPath(p.forbiddenLabels, dir, path)
+	if sourceLabel {
+		forbidden = append(slices.Clone(forbidden), sourceFileLabel)
+	}
+
+	if prefixes, ok := matchPath(p.metricPrefixes, dir, path); ok {
+		for name := range families {
+			if !slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) {
+				return nil, &policyError{"metric_prefix", fmt.Errorf("metric %q of %q doesn't have an allowed prefix %q", name, path, prefixes)}
+			}
+		}
+	}
+	var series int
+	for name, mf := range families {
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if slices.Contains(forbidden, l.GetName()) {
+					return nil, &policyError{"forbidden_label", fmt.Errorf("metric %q of %q has forbidden label %q", name, path, l.GetName())}
+				}
+			}
+			series += seriesCount(mf.GetType(), m)
+		}
+	}
+	if maxSeries, ok := matchPath(p.maxSeries, dir, path); ok && series > maxSeries {
+		return nil, &policyError{"series_limit", fmt.Errorf("%q has %d series, more than the limit of %d", path, series, maxSeries)}
+	}
+
+	if !sourceLabel {
+		return families, nil
+	}
+	// Label copies, the parsed families may be cached by the watcher.
+	labeled := make(map[string]*dto.MetricFamily, len(families))
+	for name, mf := range families {
+		c := &dto.MetricFamily{Name: mf.Name, Help: mf.Help, Type: mf.Type, Unit: mf.Unit}
+		for _, m := range mf.GetMetric() {
+			c.Metric = append(c.Metric, &dto.Metric{
+				Label:       append(slices.Clone(m.Label), &dto.LabelPair{Name: proto.String(sourceFileLabel), Value: proto.String(path)}),
+				Gauge:       m.Gauge,
+				Counter:     m.Counter,
+				Summary:     m.Summary,
+				Untyped:     m.Untyped,
+				Histogram:   m.Histogram,
+				TimestampMs: m.TimestampMs,
+			})
+		}
+		labeled[name] = c
+	}
+	return labeled, nil
+}
+
+// seriesCount returns the number of series a metric is exposed as in the text
+// format.
+func seriesCount(t dto.MetricType, m *dto.Metric) int {
+	switch t {
+	case dto.MetricType_SUMMARY:
+		return len(m.GetSummary().GetQuantile()) + 2
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		if m.GetHistogram().Schema != nil && len(m.GetHistogram().GetBucket()) == 0 {
+			return 1
+		}
+		return len(m.GetHistogram().GetBucket()) + 2
+	default:
+		return 1
+	}
+}
@@ -22,13 +22,22 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/promslog"
 	"github.com/prometheus/common/promslog/flag"
+	"google.golang.org/protobuf/proto"
 )
 
 type collectorAdapter struct {
@@ -51,8 +60,9 @@ func (a collectorAdapter) Collect(ch chan<- prometheus.Metric) {
 
 func TestTextfileCollector(t *testing.T) {
 	tests := []struct {
-		paths []string
-		out   string
+		paths           []string
+		honorTimestamps bool
+		out             string
 	}{
 		{
 			paths: []string{"fixtures/textfile/no_metric_files"},
@@ -70,6 +80,11 @@ func TestTextfileCollector(t *testing.T) {
 			paths: []string{"fixtures/textfile/client_side_timestamp"},
 			out:   "fixtures/textfile/client_side_timestamp.out",
 		},
+		{
+			paths:           []string{"fixtures/textfile/client_side_timestamp"},
+			honorTimestamps: true,
+			out:             "fixtures/textfile/client_side_timestamp_honored.out",
+		},
 		{
 			paths: []string{"fixtures/textfile/different_metric_types"},
 			out:   "fixtures/textfile/different_metric_types.out",
@@ -121,14 +136,19 @@ func TestTextfileCollector(t *testing.T) {
 			paths: []string{"fixtures/textfile/metrics_merge_different_help"},
 			out:   "fixtures/textfile/metrics_merge_different_help.out",
 		},
+		{
+			paths: []string{"fixtures/textfile/openmetrics"},
+			out:   "fixtures/textfile/openmetrics.out",
+		},
 	}
 
 	for i, test := range tests {
 		mtime := 1.0
 		c := &textFileCollector{
-			paths:  test.paths,
-			mtime:  &mtime,
-			logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			paths:           test.paths,
+			honorTimestamps: test.honorTimestamps,
+			mtime:           &mtime,
+			logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
 		}
 
 		// Suppress a log message about `nonexistent_path` not existing, this is
@@ -156,3 +176,225 @@ func TestTextfileCollector(t *testing.T) {
 		}
 	}
 }
+
+func TestTextfileMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-2 * time.Hour)
+	for name, mtime := range map[string]time.Time{
+		"fresh.prom":    time.Now(),
+		"stale.prom":    old,
+		"nightly.prom":  old,
+		"disabled.prom": old,
+	} {
+		path := filepath.Join(dir, name)
+		metric := strings.TrimSuffix(name, ".prom") + "_metric 1\n"
+		if err := os.WriteFile(path, []byte(metric), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &textFileCollector{
+		paths:  []string{dir},
+		maxAge: time.Hour,
+		maxAgeOverrides: []pathValue[time.Duration]{
+			{pattern: filepath.Join(dir, "nightly.prom"), value: 25 * time.Hour},
+			{pattern: filepath.Join(dir, "disabled.*"), value: 0},
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectorAdapter{c})
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, mf := range mfs {
+		names = append(names, mf.GetName())
+		if mf.GetName() == "node_textfile_stale_files" {
+			if got := mf.GetMetric()[0].GetGauge().GetValue(); got != 1 {
+				t.Errorf("want 1 stale file, got %v", got)
+			}
+		}
+	}
+	want := []string{
+		"disabled_metric",
+		"fresh_metric",
+		"nightly_metric",
+		"node_textfile_mtime_seconds",
+		"node_textfile_scrape_error",
+		"node_textfile_stale_files",
+	}
+	if !slices.Equal(want, names) {
+		t.Errorf("want metrics %q, got %q", want, names)
+	}
+}
+
+func TestTextfileExemplarsAndNativeHistograms(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "native.pb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	native := &dto.MetricFamily{
+		Name: proto.String("job_native_seconds"),
+		Help: proto.String("Native histogram."),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{
+			Histogram: &dto.Histogram{
+				SampleCount:   proto.Uint64(3),
+				SampleSum:     proto.Float64(4.5),
+				Schema:        proto.Int32(3),
+				ZeroThreshold: proto.Float64(1e-128),
+				ZeroCount:     proto.Uint64(0),
+				PositiveSpan:  []*dto.BucketSpan{{Offset: proto.Int32(1), Length: proto.Uint32(2)}},
+				PositiveDelta: []int64{1, 1},
+			},
+		}},
+	}
+	if err := expfmt.NewEncoder(f, expfmt.NewFormat(expfmt.TypeProtoDelim)).Encode(native); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	c := &textFileCollector{
+		paths:  []string{dir, "fixtures/textfile/openmetrics"},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectorAdapter{c})
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	families := map[string]*dto.MetricFamily{}
+	for _, mf := range mfs {
+		families[mf.GetName()] = mf
+	}
+
+	h := families["job_native_seconds"].GetMetric()[0].GetHistogram()
+	if h.GetSchema() != 3 || !slices.Equal(h.GetPositiveDelta(), []int64{1, 1}) || h.GetPositiveSpan()[0].GetOffset() != 1 {
+		t.Errorf("native histogram not passed through, got %v", h)
+	}
+
+	for _, m := range families["job_runs_total"].GetMetric() {
+		if m.GetLabel()[0].GetValue() != "success" {
+			continue
+		}
+		e := m.GetCounter().GetExemplar()
+		if e.GetValue() != 1 || e.GetLabel()[0].GetValue() != "abc123" || e.GetTimestamp().AsTime().UnixMilli() != 1700000000500 {
+			t.Errorf("unexpected counter exemplar %v", e)
+		}
+		if got := m.GetCounter().GetCreatedTimestamp().AsTime().Unix(); got != 1700000000 {
+			t.Errorf("want created timestamp 1700000000, got %d", got)
+		}
+	}
+
+	buckets := families["job_duration_seconds"].GetMetric()[0].GetHistogram().GetBucket()
+	if e := buckets[1].GetExemplar(); e.GetValue() != 7.5 || e.GetLabel()[0].GetValue() != "def456" {
+		t.Errorf("unexpected bucket exemplar %v", e)
+	}
+}
+
+func TestParseOpenMetricsErrors(t *testing.T) {
+	for name, input := range map[string]string{
+		"missing EOF":         "a 1\n",
+		"content after EOF":   "a 1\n# EOF\na 2\n",
+		"not contiguous":      "a 1\nb 1\na 2\n# EOF\n",
+		"metadata after data": "a 1\n# TYPE a gauge\n# EOF\n",
+		"unknown type":        "# TYPE a foo\n# EOF\n",
+		"bucket without le":   "# TYPE a histogram\na_bucket 1\n# EOF\n",
+		"exemplar on gauge":   "# TYPE a gauge\na 1 # {id=\"1\"} 1\n# EOF\n",
+		"unterminated label":  "a{b=\"c} 1\n# EOF\n",
+		"invalid value":       "a one\n# EOF\n",
+		"counter sans total":  "# TYPE a counter\na_created 1\n# EOF\n",
+		"reserved label":      "a{__name__=\"b\"} 1\n# EOF\n",
+		"repeated label":      "a{a=\"x\",a=\"y\"} 1\n# EOF\n",
+		"invalid UTF-8 value": "a{b=\"\xff\"} 1\n# EOF\n",
+	} {
+		if _, err := parseOpenMetrics(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected error parsing %q", name, input)
+		}
+	}
+}
+
+func TestTextfileInvalidLabels(t *testing.T) {
+	for name, labels := range map[string][]*dto.LabelPair{
+		"reserved label": {{Name: proto.String("__name__"), Value: proto.String("b")}},
+		"repeated label": {{Name: proto.String("a"), Value: proto.String("x")}, {Name: proto.String("a"), Value: proto.String("y")}},
+		"invalid UTF-8":  {{Name: proto.String("a"), Value: proto.String("\xff")}},
+	} {
+		mf := &dto.MetricFamily{
+			Name:   proto.String("a"),
+			Help:   proto.String("a"),
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Label: labels, Gauge: &dto.Gauge{Value: proto.Float64(1)}}},
+		}
+		var b strings.Builder
+		if err := expfmt.NewEncoder(&b, expfmt.NewFormat(expfmt.TypeProtoDelim).WithEscapingScheme(model.NoEscaping)).Encode(mf); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parseTextFile(strings.NewReader(b.String()), ".pb"); err == nil {
+			t.Errorf("%s: expected error parsing protobuf", name)
+		}
+
+		// Metrics which can't be created are reported, not panicked on.
+		ch := make(chan prometheus.Metric, 1)
+		if err := convertMetricFamily(mf, ch, false, slog.New(slog.NewTextHandler(io.Discard, nil))); err == nil {
+			t.Errorf("%s: expected error converting metric", name)
+		}
+		if len(ch) != 0 {
+			t.Errorf("%s: want no metric sent, got %d", name, len(ch))
+		}
+	}
+}
+
+func TestTextfilePolicies(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"ok.prom":        "job_a 1\n",
+		"prefix.prom":    "node_load1 5\n",
+		"forbidden.prom": "job_b{instance=\"x\"} 1\n",
+		"limit.prom":     "job_c{n=\"1\"} 1\njob_c{n=\"2\"} 1\njob_c{n=\"3\"} 1\n",
+		"source.prom":    "job_d{source_file=\"x\"} 1\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &textFileCollector{
+		paths: []string{dir},
+		policies: textFilePolicies{
+			metricPrefixes:  []pathValue[[]string]{{pattern: dir, value: []string{"job_"}}},
+			forbiddenLabels: []pathValue[[]string]{{pattern: dir, value: []string{"instance", "job"}}},
+			maxSeries:       []pathValue[int]{{pattern: filepath.Join(dir, "limit.prom"), value: 2}},
+			sourceLabel:     []pathValue[bool]{{pattern: filepath.Join(dir, "*"), value: true}},
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectorAdapter{c})
+
+	want := fmt.Sprintf(`
+# HELP job_a Metric read from %[1]s/ok.prom
+# TYPE job_a untyped
+job_a{source_file="%[1]s/ok.prom"} 1
+# HELP node_textfile_file_error 1 if a textfile was rejected because it breaks the policy of its directory.
+# TYPE node_textfile_file_error gauge
+node_textfile_file_error{file="%[1]s/forbidden.prom",reason="forbidden_label"} 1
+node_textfile_file_error{file="%[1]s/limit.prom",reason="series_limit"} 1
+node_textfile_file_error{file="%[1]s/prefix.prom",reason="metric_prefix"} 1
+node_textfile_file_error{file="%[1]s/source.prom",reason="forbidden_label"} 1
+# HELP node_textfile_scrape_error 1 if there was an error opening or reading a file, 0 otherwise
+# TYPE node_textfile_scrape_error gauge
+node_textfile_scrape_error 1
+`, dir)
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "job_a", "job_b", "job_c", "job_d", "node_load1", "node_textfile_file_error", "node_textfile_scrape_error"); err != nil {
+		t.Error(err)
+	}
+}
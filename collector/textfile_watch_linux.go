@@ -0,0 +1,203 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !notextfile
+
+package collector
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const textFileWatchMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_CLOSE_WRITE | unix.IN_MODIFY | unix.IN_ATTRIB | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF | unix.IN_ONLYDIR
+
+// textFileWatcher caches the listings of the textfile directories and the
+// results of parsing their files, and drops them when inotify reports a
+// change. Events are queued by the kernel and read before using the cache,
+// so no goroutine is needed.
+type textFileWatcher struct {
+	mtx sync.Mutex
+	fd  int
+	// dirs are the watched directories by path and by watch descriptor.
+	dirs    map[string]*watchedDir
+	watches map[int]*watchedDir
+}
+
+type watchedDir struct {
+	wd    int
+	paths []string
+	// entries is the cached listing, nil if it has to be read again.
+	entries []os.DirEntry
+	files   map[string]*textFileResult
+}
+
+func newTextFileWatcher() (*textFileWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialize inotify: %w", err)
+	}
+	return &textFileWatcher{
+		fd:      fd,
+		dirs:    map[string]*watchedDir{},
+		watches: map[int]*watchedDir{},
+	}, nil
+}
+
+// readDir lists a directory, watching it from then on. Symlinks to
+// directories aren't watched, as the watch would stay on their target when
+// they are changed.
+func (w *textFileWatcher) readDir(path string) ([]os.DirEntry, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if err := w.sync(); err != nil {
+		return os.ReadDir(path)
+	}
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		return os.ReadDir(path)
+	}
+	d, err := w.watch(path)
+	if err != nil {
+		return os.ReadDir(path)
+	}
+	if d.entries == nil {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+	}
+	return d.entries, nil
+}
+
+// processFile returns the result of process for a file, calling it only if
+// the file changed since the last call. Files in directories which aren't
+// watched are always processed, and so are symlinks, as the changes of their
+// targets aren't reported.
+func (w *textFileWatcher) processFile(dir, name string, process func(dir, name string) *textFileResult) *textFileResult {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if err := w.sync(); err != nil {
+		return process(dir, name)
+	}
+	d, ok := w.dirs[filepath.Clean(dir)]
+	if !ok {
+		return process(dir, name)
+	}
+	if fi, err := os.Lstat(filepath.Join(dir, name)); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		delete(d.files, name)
+		return process(dir, name)
+	}
+	if r, ok := d.files[name]; ok {
+		return r
+	}
+	r := process(dir, name)
+	d.files[name] = r
+	return r
+}
+
+// Close stops watching. The watcher keeps working without caching.
+func (w *textFileWatcher) Close() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.fd >= 0 {
+		unix.Close(w.fd)
+		w.fd = -1
+	}
+	clear(w.dirs)
+	clear(w.watches)
+}
+
+func (w *textFileWatcher) watch(path string) (*watchedDir, error) {
+	path = filepath.Clean(path)
+	if d, ok := w.dirs[path]; ok {
+		return d, nil
+	}
+	wd, err := unix.InotifyAddWatch(w.fd, path, textFileWatchMask)
+	if err != nil {
+		return nil, err
+	}
+	// Different paths may name the same directory.
+	d, ok := w.watches[wd]
+	if !ok {
+		d = &watchedDir{wd: wd, files: map[string]*textFileResult{}}
+		w.watches[wd] = d
+	}
+	d.paths = append(d.paths, path)
+	w.dirs[path] = d
+	return d, nil
+}
+
+// sync reads the queued events and drops what they invalidate from the
+// cache.
+func (w *textFileWatcher) sync() error {
+	if w.fd < 0 {
+		return errors.New("watcher is closed")
+	}
+	var buf [64 * (unix.SizeofInotifyEvent + unix.NAME_MAX + 1)]byte
+	for {
+		n, err := unix.Read(w.fd, buf[:])
+		if errors.Is(err, unix.EAGAIN) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			name := string(bytes.TrimRight(buf[offset+unix.SizeofInotifyEvent:offset+unix.SizeofInotifyEvent+int(event.Len)], "\x00"))
+			offset += unix.SizeofInotifyEvent + int(event.Len)
+			w.handle(int(event.Wd), event.Mask, name)
+		}
+	}
+}
+
+func (w *textFileWatcher) handle(wd int, mask uint32, name string) {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		// Events were lost, start over.
+		for _, d := range w.watches {
+			d.entries = nil
+			clear(d.files)
+		}
+		return
+	}
+	d, ok := w.watches[wd]
+	if !ok {
+		return
+	}
+	switch {
+	case mask&(unix.IN_IGNORED|unix.IN_DELETE_SELF|unix.IN_MOVE_SELF) != 0:
+		// The directory is gone or elsewhere, watch it again by path if
+		// it is listed again.
+		if mask&unix.IN_IGNORED == 0 {
+			unix.InotifyRmWatch(w.fd, uint32(wd))
+		}
+		for _, path := range d.paths {
+			delete(w.dirs, path)
+		}
+		delete(w.watches, wd)
+	case mask&(unix.IN_CREATE|unix.IN_DELETE|unix.IN_MOVED_FROM|unix.IN_MOVED_TO) != 0:
+		d.entries = nil
+		delete(d.files, name)
+	default:
+		delete(d.files, name)
+	}
+}
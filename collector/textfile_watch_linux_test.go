@@ -0,0 +1,157 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !notextfile
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTextfileWatch(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		// Replace the file atomically, as recommended for textfiles.
+		tmp := filepath.Join(dir, name+".tmp")
+		if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.prom", "a 1\n")
+	write("b.prom", "b 1\n")
+
+	w, err := newTextFileWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &textFileCollector{
+		paths:   []string{dir},
+		watcher: w,
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	defer c.Close()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectorAdapter{c})
+
+	scrape := func(want string, names ...string) {
+		t.Helper()
+		if err := testutil.GatherAndCompare(registry, strings.NewReader(want), names...); err != nil {
+			t.Error(err)
+		}
+	}
+	scrape(`
+# HELP a Metric read from `+dir+`/a.prom
+# TYPE a untyped
+a 1
+# HELP b Metric read from `+dir+`/b.prom
+# TYPE b untyped
+b 1
+`, "a", "b")
+	cached := w.dirs[dir].files["b.prom"]
+	if cached == nil {
+		t.Fatal("b.prom not cached")
+	}
+
+	// Only the changed file is parsed again.
+	write("a.prom", "a 2\n")
+	scrape(`
+# HELP a Metric read from `+dir+`/a.prom
+# TYPE a untyped
+a 2
+`, "a")
+	if w.dirs[dir].files["b.prom"] != cached {
+		t.Error("unchanged b.prom parsed again")
+	}
+
+	// Help text conflicts are still detected across cached files.
+	write("c.prom", "# HELP e Some help.\ne 1\n")
+	write("d.prom", "# HELP e Different help.\ne 2\n")
+	scrape(`
+# HELP node_textfile_scrape_error 1 if there was an error opening or reading a file, 0 otherwise
+# TYPE node_textfile_scrape_error gauge
+node_textfile_scrape_error 1
+`, "node_textfile_scrape_error")
+	if err := os.Remove(filepath.Join(dir, "d.prom")); err != nil {
+		t.Fatal(err)
+	}
+	scrape(`
+# HELP node_textfile_scrape_error 1 if there was an error opening or reading a file, 0 otherwise
+# TYPE node_textfile_scrape_error gauge
+node_textfile_scrape_error 0
+`, "node_textfile_scrape_error")
+
+	// The mtimes are the ones of the files.
+	stat, err := os.Stat(filepath.Join(dir, "a.prom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != "node_textfile_mtime_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if m.GetLabel()[0].GetValue() == filepath.Join(dir, "a.prom") && int64(m.GetGauge().GetValue()) != stat.ModTime().Unix() {
+				t.Errorf("want mtime %d, got %v", stat.ModTime().Unix(), m.GetGauge().GetValue())
+			}
+		}
+	}
+
+	// Symlinked files are read again, as the changes of their targets
+	// aren't reported.
+	target := filepath.Join(t.TempDir(), "s.prom")
+	if err := os.WriteFile(target, []byte("s 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "s.prom")); err != nil {
+		t.Fatal(err)
+	}
+	scrape(`
+# HELP s Metric read from `+dir+`/s.prom
+# TYPE s untyped
+s 1
+`, "s")
+	if err := os.WriteFile(target, []byte("s 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	scrape(`
+# HELP s Metric read from `+dir+`/s.prom
+# TYPE s untyped
+s 2
+`, "s")
+
+	// A closed watcher keeps working without caching.
+	c.Close()
+	write("b.prom", "b 2\n")
+	scrape(`
+# HELP b Metric read from `+dir+`/b.prom
+# TYPE b untyped
+b 2
+`, "b")
+}
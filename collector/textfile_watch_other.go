@@ -0,0 +1,38 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux && !notextfile
+
+package collector
+
+import (
+	"errors"
+	"os"
+)
+
+// textFileWatcher is only implemented on Linux, using inotify.
+type textFileWatcher struct{}
+
+func newTextFileWatcher() (*textFileWatcher, error) {
+	return nil, errors.New("watching textfile directories is only supported on Linux")
+}
+
+func (w *textFileWatcher) readDir(path string) ([]os.DirEntry, error) {
+	return os.ReadDir(path)
+}
+
+func (w *textFileWatcher) processFile(dir, name string, process func(dir, name string) *textFileResult) *textFileResult {
+	return process(dir, name)
+}
+
+func (w *textFileWatcher) Close() {}
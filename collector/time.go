@@ -27,6 +27,7 @@ type timeCollector struct {
 	zone                  typedDesc
 	clocksourcesAvailable typedDesc
 	clocksourceCurrent    typedDesc
+	paths                 Paths
 	logger                *slog.Logger
 }
 
@@ -59,6 +60,7 @@ func NewTimeCollector(logger *slog.Logger) (Collector, error) {
 			"Current clocksource read from '/sys/devices/system/clocksource'.",
 			[]string{"device", "clocksource"}, nil,
 		), prometheus.GaugeValue},
+		paths:  currentPaths(),
 		logger: logger,
 	}, nil
 }
@@ -38,6 +38,7 @@ var (
 
 type vmStatCollector struct {
 	fieldPattern *regexp.Regexp
+	paths        Paths
 	logger       *slog.Logger
 }
 
@@ -50,12 +51,13 @@ func NewvmStatCollector(logger *slog.Logger) (Collector, error) {
 	pattern := regexp.MustCompile(*vmStatFields)
 	return &vmStatCollector{
 		fieldPattern: pattern,
+		paths:        currentPaths(),
 		logger:       logger,
 	}, nil
 }
 
 func (c *vmStatCollector) Update(ch chan<- prometheus.Metric) error {
-	file, err := os.Open(procFilePath("vmstat"))
+	file, err := os.Open(c.paths.procFilePath("vmstat"))
 	if err != nil {
 		return err
 	}
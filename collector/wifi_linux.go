@@ -29,7 +29,8 @@ import (
 )
 
 type wifiCollector struct {
-	logger *slog.Logger
+	fixtures string
+	logger   *slog.Logger
 }
 
 var (
@@ -146,12 +147,13 @@ var (
 // NewWifiCollector returns a new Collector exposing Wifi statistics.
 func NewWifiCollector(logger *slog.Logger) (Collector, error) {
 	return &wifiCollector{
-		logger: logger,
+		fixtures: *collectorWifi,
+		logger:   logger,
 	}, nil
 }
 
 func (c *wifiCollector) Update(ch chan<- prometheus.Metric) error {
-	stat, err := newWifiStater(*collectorWifi)
+	stat, err := newWifiStater(c.fixtures)
 	if err != nil {
 		// Cannot access wifi metrics, report no error.
 		if errors.Is(err, os.ErrNotExist) {
@@ -54,6 +54,7 @@ type zfsCollector struct {
 	linuxZpoolObjsetPath string
 	linuxZpoolStatePath  string
 	linuxPathMap         map[string]string
+	paths                Paths
 	logger               *slog.Logger
 }
 
@@ -77,6 +78,7 @@ func NewZFSCollector(logger *slog.Logger) (Collector, error) {
 			"zfs_zfetch":      "zfetchstats",
 			"zfs_zil":         "zil",
 		},
+		paths:  currentPaths(),
 		logger: logger,
 	}, nil
 }
@@ -105,12 +107,12 @@ func (c *zfsCollector) Update(ch chan<- prometheus.Metric) error {
 }
 
 func (c *zfsCollector) openProcFile(path string) (*os.File, error) {
-	file, err := os.Open(procFilePath(path))
+	file, err := os.Open(c.paths.procFilePath(path))
 	if err != nil {
 		// file not found error can occur if:
 		// 1. zfs module is not loaded
 		// 2. zfs version does not have the feature with metrics -- ok to ignore
-		c.logger.Debug("Cannot open file for reading", "path", procFilePath(path))
+		c.logger.Debug("Cannot open file for reading", "path", c.paths.procFilePath(path))
 		return nil, errZFSNotAvailable
 	}
 	return file, nil
@@ -136,7 +138,7 @@ func (c *zfsCollector) updateZfsStats(subsystem string, ch chan<- prometheus.Met
 }
 
 func (c *zfsCollector) updatePoolStats(ch chan<- prometheus.Metric) error {
-	zpoolPaths, err := filepath.Glob(procFilePath(filepath.Join(c.linuxProcpathBase, c.linuxZpoolIoPath)))
+	zpoolPaths, err := filepath.Glob(c.paths.procFilePath(filepath.Join(c.linuxProcpathBase, c.linuxZpoolIoPath)))
 	if err != nil {
 		return err
 	}
@@ -158,7 +160,7 @@ func (c *zfsCollector) updatePoolStats(ch chan<- prometheus.Metric) error {
 		}
 	}
 
-	zpoolObjsetPaths, err := filepath.Glob(procFilePath(filepath.Join(c.linuxProcpathBase, c.linuxZpoolObjsetPath)))
+	zpoolObjsetPaths, err := filepath.Glob(c.paths.procFilePath(filepath.Join(c.linuxProcpathBase, c.linuxZpoolObjsetPath)))
 	if err != nil {
 		return err
 	}
@@ -180,7 +182,7 @@ func (c *zfsCollector) updatePoolStats(ch chan<- prometheus.Metric) error {
 		}
 	}
 
-	zpoolStatePaths, err := filepath.Glob(procFilePath(filepath.Join(c.linuxProcpathBase, c.linuxZpoolStatePath)))
+	zpoolStatePaths, err := filepath.Glob(c.paths.procFilePath(filepath.Join(c.linuxProcpathBase, c.linuxZpoolStatePath)))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,73 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/node_exporter/collector"
+)
+
+var collectorsTemplate = template.Must(template.New("collectors").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Node Exporter Collectors</title>
+<style>
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.disabled { color: #999; }
+.failed { background: #fdd; }
+</style>
+</head>
+<body>
+<h1>Collectors</h1>
+<p><a href="?format=json">JSON</a></p>
+<table>
+<tr><th>Name</th><th>Enabled</th><th>Options</th><th>Last run</th><th>Duration</th><th>Series</th><th>Last error</th></tr>
+{{range .}}<tr{{if not .Enabled}} class="disabled"{{else if and .LastRun (not .LastRun.Success)}} class="failed"{{end}}>
+<td>{{.Name}}</td>
+<td>{{.Enabled}}{{if .Forced}} (forced){{end}}</td>
+<td>{{range $option, $values := .Options}}{{$option}}={{join $values ","}}<br>{{end}}</td>
+{{with .LastRun}}<td>{{.Time.Format "2006-01-02T15:04:05Z07:00"}}</td><td>{{printf "%.3fs" .DurationSeconds}}{{if .TimedOut}} (timed out){{end}}</td><td>{{.Series}}{{if .Truncated}} (truncated){{end}}</td>{{else}}<td>never</td><td></td><td></td>{{end}}
+<td>{{with .LastError}}{{.Time.Format "2006-01-02T15:04:05Z07:00"}}: {{.Error}}{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// collectorsHandler serves the status of all collectors, as HTML or, if
+// requested with the format query parameter or the Accept header, as JSON.
+func collectorsHandler(logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := collector.Statuses()
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(statuses); err != nil {
+				logger.Error("Couldn't write collectors status", "err", err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := collectorsTemplate.Execute(w, statuses); err != nil {
+			logger.Error("Couldn't write collectors status", "err", err)
+		}
+	})
+}
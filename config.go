@@ -0,0 +1,148 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.yaml.in/yaml/v2"
+
+	"github.com/prometheus/node_exporter/collector"
+)
+
+var (
+	configReloadSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "node_exporter",
+		Name:      "config_last_reload_successful",
+		Help:      "Whether the last configuration reload attempt was successful.",
+	})
+	configReloadSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "node_exporter",
+		Name:      "config_last_reload_success_timestamp_seconds",
+		Help:      "Timestamp of the last successful configuration reload.",
+	})
+)
+
+// config is the format of the file passed via --config.file.
+type config struct {
+	Collectors map[string]collector.Config `yaml:"collectors,omitempty"`
+	Profiles   map[string]profileConfig    `yaml:"profiles,omitempty"`
+	Targets    map[string]targetConfig     `yaml:"targets,omitempty"`
+	// MetricRelabelConfigs are applied to all metrics of the collectors,
+	// after the rules of the individual collectors.
+	MetricRelabelConfigs []*collector.RelabelConfig `yaml:"metric_relabel_configs,omitempty"`
+	labelsConfig         `yaml:",inline"`
+}
+
+// profileConfig defines a scrape profile, selected with ?profile=<name>. It
+// serves exactly the collectors listed, each optionally with settings of its
+// own on top of the global ones.
+type profileConfig struct {
+	Collectors map[string]collector.Config `yaml:"collectors"`
+}
+
+// targetConfig defines a target of the /probe endpoint, selected with
+// ?target=<name>, such as the root filesystem of a container. Its collectors
+// read from the given paths and default to the enabled collectors.
+type targetConfig struct {
+	collector.Paths `yaml:",inline"`
+	Collectors      map[string]collector.Config `yaml:"collectors,omitempty"`
+}
+
+func loadConfig(filename string) (*config, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config{}
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %w", filename, err)
+	}
+	for name, p := range cfg.Profiles {
+		if len(p.Collectors) == 0 {
+			return nil, fmt.Errorf("profile %s: no collectors", name)
+		}
+	}
+	return cfg, nil
+}
+
+// reloadConfig loads the configuration file, if any, applies it to the
+// collectors, re-reads the label files and rebuilds the unfiltered handler.
+// On failure, the previous configuration stays in effect.
+func (h *handler) reloadConfig() (err error) {
+	defer func() {
+		if err != nil {
+			h.logger.Error("Couldn't reload configuration", "file", h.configFile, "err", err)
+			configReloadSuccess.Set(0)
+			return
+		}
+		h.logger.Info("Loaded configuration", "file", h.configFile)
+		configReloadSuccess.Set(1)
+		configReloadSeconds.SetToCurrentTime()
+	}()
+
+	cfg := &config{}
+	if h.configFile != "" {
+		if cfg, err = loadConfig(h.configFile); err != nil {
+			return err
+		}
+	}
+	labels, err := targetLabels(h.cliLabels, cfg.labelsConfig)
+	if err != nil {
+		return err
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if err := collector.ApplyConfig(cfg.Collectors, cfg.MetricRelabelConfigs); err != nil {
+		return err
+	}
+	enabledCollectors := h.enabledCollectors
+	rollback := func() {
+		h.enabledCollectors = enabledCollectors
+		previous := &config{}
+		if h.config != nil {
+			previous = h.config
+		}
+		if err := collector.ApplyConfig(previous.Collectors, previous.MetricRelabelConfigs); err != nil {
+			h.logger.Error("Couldn't restore previous configuration", "err", err)
+		}
+	}
+	handler, err := h.unfilteredInnerHandler()
+	if err != nil {
+		rollback()
+		return err
+	}
+	profiles, err := h.newProfiles(cfg.Profiles)
+	if err != nil {
+		rollback()
+		return err
+	}
+	targets, err := h.newTargets(cfg.Targets)
+	if err != nil {
+		closeHandlers(profiles)
+		rollback()
+		return err
+	}
+	closeHandlers(h.profiles)
+	closeHandlers(h.targets)
+	h.unfilteredHandler = handler.handler
+	h.profiles = profiles
+	h.targets = targets
+	h.config = cfg
+	h.labels = labels
+	return nil
+}
@@ -42,6 +42,7 @@ enabled_collectors=$(cat << COLLECTORS
   btrfs
   buddyinfo
   cgroups
+  cgroupv2
   conntrack
   cpu
   cpufreq
@@ -108,7 +109,7 @@ cd "$(dirname $0)"
 port="$((10000 + (RANDOM % 10000)))"
 tmpdir=$(mktemp -d /tmp/node_exporter_e2e_test.XXXXXX)
 
-skip_re="^(go_|node_exporter_build_info|node_scrape_collector_duration_seconds|process_|node_textfile_mtime_seconds|node_time_(zone|seconds)|node_network_(receive|transmit)_(bytes|packets)_total)"
+skip_re="^(go_|node_exporter_build_info|node_scrape_collector_(duration_seconds|series)|process_|node_textfile_mtime_seconds|node_time_(zone|seconds)|node_network_(receive|transmit)_(bytes|packets)_total)"
 
 case "${arch}" in
   aarch64|ppc64le) fixture_metrics='collector/fixtures/e2e-64k-page-output.txt' ;;
@@ -173,6 +174,7 @@ collector_flags=$(cat << FLAGS
   --collector.netclass.ignore-invalid-speed
   --collector.netclass.ignored-devices=(dmz|int)
   --collector.netdev.device-include=lo
+  --collector.pressure.cgroup-include=/system\.slice/.+
   --collector.qdisc.device-include=(wlan0|eth0)
   --collector.qdisc.fixtures=collector/fixtures/qdisc/
   --collector.stat.softirq
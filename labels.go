@@ -0,0 +1,108 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// labelsConfig holds the constant labels added to all series, given directly
+// or read from files of <name>=<value> lines.
+type labelsConfig struct {
+	Labels map[string]string `yaml:"target_labels,omitempty"`
+	Files  []string          `yaml:"target_label_files,omitempty"`
+}
+
+// targetLabels merges the labels of configs, later ones overriding earlier
+// ones. Within a config, the labels given directly override the ones read
+// from its files. A label with an empty value is removed.
+func targetLabels(configs ...labelsConfig) (prometheus.Labels, error) {
+	labels := prometheus.Labels{}
+	for _, cfg := range configs {
+		for _, file := range cfg.Files {
+			fileLabels, err := readLabelFile(file)
+			if err != nil {
+				return nil, err
+			}
+			maps.Copy(labels, fileLabels)
+		}
+		for _, name := range slices.Sorted(maps.Keys(cfg.Labels)) {
+			if err := checkLabelName(name); err != nil {
+				return nil, err
+			}
+			labels[name] = cfg.Labels[name]
+		}
+	}
+	maps.DeleteFunc(labels, func(_, value string) bool { return value == "" })
+	return labels, nil
+}
+
+// readLabelFile reads labels from a file of <name>=<value> lines. Empty
+// lines and lines starting with # are ignored.
+func readLabelFile(path string) (prometheus.Labels, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	labels := prometheus.Labels{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected <name>=<value>", path, n)
+		}
+		name = strings.TrimSpace(name)
+		if err := checkLabelName(name); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		labels[name] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read %s: %w", path, err)
+	}
+	return labels, nil
+}
+
+// reservedLabelNames are the labels of the series exposed on every scrape,
+// like node_scrape_collector_*, node_exporter_build_info, histograms and
+// summaries or the exporter's own go_*, process_* and promhttp_* metrics. A
+// target label named alike would collide with them and fail every scrape.
+var reservedLabelNames = []string{
+	"branch", "cause", "code", "collector", "goarch", "goos", "goversion",
+	"le", "quantile", "result", "revision", "tags", "version",
+}
+
+func checkLabelName(name string) error {
+	if !model.LegacyValidation.IsValidLabelName(name) || strings.HasPrefix(name, "__") {
+		return fmt.Errorf("invalid label name %q", name)
+	}
+	if slices.Contains(reservedLabelNames, name) {
+		return fmt.Errorf("label name %q is reserved for the exporter's own metrics", name)
+	}
+	return nil
+}
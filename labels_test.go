@@ -0,0 +1,105 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"log/slog"
+	"maps"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/prometheus/node_exporter/collector"
+)
+
+func TestTargetLabels(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "node-labels")
+	content := "# From DMI.\nrack = r12\n\nvendor=acme\nrole=db\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	labels, err := targetLabels(
+		labelsConfig{Labels: map[string]string{"datacenter": "eu1", "role": "web"}},
+		labelsConfig{Labels: map[string]string{"vendor": ""}, Files: []string{file}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := prometheus.Labels{"datacenter": "eu1", "rack": "r12", "role": "db"}
+	if !maps.Equal(labels, want) {
+		t.Errorf("want %v, got %v", want, labels)
+	}
+
+	for name, content := range map[string]string{
+		"invalid-name": "1rack=r12\n",
+		"reserved":     "__rack=r12\n",
+		"collector":    "collector=cpu\n",
+		"no-value":     "rack\n",
+	} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := targetLabels(labelsConfig{Files: []string{file}}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := targetLabels(labelsConfig{Files: []string{filepath.Join(dir, "missing")}}); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestMetricsHandlerTargetLabels(t *testing.T) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_value", Help: "Test value."})
+	gauge.Set(1)
+	exporterGauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_exporter_value", Help: "Test value of the exporter."})
+	h := &handler{
+		collectors:              []prometheus.Collector{gauge},
+		labels:                  prometheus.Labels{"datacenter": "eu1"},
+		includeExporterMetrics:  true,
+		exporterMetricsRegistry: prometheus.NewRegistry(),
+		logger:                  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	h.exporterMetricsRegistry.MustRegister(exporterGauge)
+	handler := h.metricsHandler(&collector.NodeCollector{})
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+		return w.Body.String()
+	}
+	body := scrape()
+	if !strings.Contains(body, `test_value{datacenter="eu1"} 1`) {
+		t.Errorf("want target label on test_value, got:\n%s", body)
+	}
+	// The exporter's own metrics are labeled too.
+	for _, want := range []string{`test_exporter_value{datacenter="eu1"} 0`, `promhttp_metric_handler_requests_total{code="200",datacenter="eu1"}`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("want %s, got:\n%s", want, body)
+		}
+	}
+
+	// Labels replaced on reload apply to the next scrape.
+	h.labels = prometheus.Labels{"datacenter": "eu2", "rack": "r12"}
+	if body := scrape(); !strings.Contains(body, `test_value{datacenter="eu2",rack="r12"} 1`) {
+		t.Errorf("want replaced target labels on test_value, got:\n%s", body)
+	}
+}
@@ -14,15 +14,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
 	_ "net/http/pprof"
+	"net/url"
 	"os"
+	"os/signal"
 	"os/user"
 	"runtime"
 	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/prometheus/common/promslog"
 	"github.com/prometheus/common/promslog/flag"
@@ -32,9 +41,11 @@ import (
 	promcollectors "github.com/prometheus/client_golang/prometheus/collectors"
 	versioncollector "github.com/prometheus/client_golang/prometheus/collectors/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/version"
 	"github.com/prometheus/exporter-toolkit/web"
 	"github.com/prometheus/exporter-toolkit/web/kingpinflag"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/prometheus/node_exporter/collector"
 )
@@ -43,49 +54,133 @@ import (
 // created on the fly, if filtering is requested. Create instances with
 // newHandler.
 type handler struct {
+	// mtx protects unfilteredHandler, enabledCollectors, profiles, targets,
+	// config and labels, which are replaced on configuration reloads.
+	mtx               sync.RWMutex
 	unfilteredHandler http.Handler
 	// enabledCollectors list is used for logging and filtering
 	enabledCollectors []string
+	// profiles and targets hold the pre-built handlers of the scrape
+	// profiles and of the targets served on /probe, keyed by name.
+	profiles map[string]prebuiltHandler
+	targets  map[string]prebuiltHandler
+	// configFile is the optional configuration file, config its currently
+	// applied content.
+	configFile string
+	config     *config
+	// cliLabels are the target labels given on the command line, labels
+	// the ones currently added to all series, see targetLabels.
+	cliLabels labelsConfig
+	labels    prometheus.Labels
+	// collectors are served along with the node collector's metrics.
+	collectors []prometheus.Collector
 	// exporterMetricsRegistry is a separate registry for the metrics about
 	// the exporter itself.
 	exporterMetricsRegistry *prometheus.Registry
 	includeExporterMetrics  bool
 	maxRequests             int
-	logger                  *slog.Logger
+	// inFlightSem limits the number of concurrent scrapes to maxRequests.
+	inFlightSem chan struct{}
+	// scrapeTimeoutOffset is subtracted from the scrape timeout announced
+	// by Prometheus to leave time for writing the response.
+	scrapeTimeoutOffset time.Duration
+	logger              *slog.Logger
 }
 
-func newHandler(includeExporterMetrics bool, maxRequests int, logger *slog.Logger) *handler {
+func newHandler(includeExporterMetrics bool, maxRequests int, scrapeTimeoutOffset time.Duration, configFile string, cliLabels labelsConfig, logger *slog.Logger) (*handler, error) {
 	h := &handler{
 		exporterMetricsRegistry: prometheus.NewRegistry(),
 		includeExporterMetrics:  includeExporterMetrics,
 		maxRequests:             maxRequests,
+		scrapeTimeoutOffset:     scrapeTimeoutOffset,
+		configFile:              configFile,
+		cliLabels:               cliLabels,
 		logger:                  logger,
 	}
+	if maxRequests > 0 {
+		h.inFlightSem = make(chan struct{}, maxRequests)
+	}
 	if h.includeExporterMetrics {
 		h.exporterMetricsRegistry.MustRegister(
 			promcollectors.NewProcessCollector(promcollectors.ProcessCollectorOpts{}),
 			promcollectors.NewGoCollector(),
 		)
 	}
-	innerHandler, err := h.innerHandler()
+	if h.reloadable() {
+		if err := h.reloadConfig(); err != nil {
+			return nil, err
+		}
+		return h, nil
+	}
+	labels, err := targetLabels(cliLabels)
 	if err != nil {
-		panic(fmt.Sprintf("Couldn't create metrics handler: %s", err))
+		return nil, err
 	}
-	h.unfilteredHandler = innerHandler
-	return h
+	h.labels = labels
+	innerHandler, err := h.unfilteredInnerHandler()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create metrics handler: %w", err)
+	}
+	h.unfilteredHandler = innerHandler.handler
+	return h, nil
+}
+
+// reloadable returns whether there is a configuration file or label files to
+// reload on SIGHUP or on a POST to /-/reload.
+func (h *handler) reloadable() bool {
+	return h.configFile != "" || len(h.cliLabels.Files) > 0
 }
 
 // ServeHTTP implements http.Handler.
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r, cancel, ok := h.withScrapeTimeout(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
 	collects := r.URL.Query()["collect[]"]
 	h.logger.Debug("collect query:", "collects", collects)
 
 	excludes := r.URL.Query()["exclude[]"]
 	h.logger.Debug("exclude query:", "excludes", excludes)
 
-	if len(collects) == 0 && len(excludes) == 0 {
+	options, err := collectorOptions(r.URL.Query())
+	if err != nil {
+		h.logger.Debug("rejecting options query", "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Couldn't parse options: %s", err)
+		return
+	}
+	h.logger.Debug("options query:", "options", options)
+
+	h.mtx.RLock()
+	unfilteredHandler, enabledCollectors, profiles := h.unfilteredHandler, h.enabledCollectors, h.profiles
+	h.mtx.RUnlock()
+
+	if r.URL.Query().Has("profile") {
+		name := r.URL.Query().Get("profile")
+		h.logger.Debug("profile query:", "profile", name)
+		if len(collects) > 0 || len(excludes) > 0 || len(options) > 0 {
+			h.logger.Debug("rejecting profile query combined with collect, exclude or options queries")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Combined profile and collect, exclude or options queries are not allowed."))
+			return
+		}
+		p, ok := profiles[name]
+		if !ok {
+			h.logger.Debug("rejecting unknown profile", "profile", name)
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Unknown profile: %q", name)
+			return
+		}
+		p.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if len(collects) == 0 && len(excludes) == 0 && len(options) == 0 {
 		// No filters, use the prepared unfiltered handler.
-		h.unfilteredHandler.ServeHTTP(w, r)
+		unfilteredHandler.ServeHTTP(w, r)
 		return
 	}
 
@@ -100,7 +195,7 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if len(excludes) > 0 {
 		// In exclude mode, filtered collectors = enabled - excludeed.
 		f := []string{}
-		for _, c := range h.enabledCollectors {
+		for _, c := range enabledCollectors {
 			if (slices.Index(excludes, c)) == -1 {
 				f = append(f, c)
 			}
@@ -109,74 +204,274 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// To serve filtered metrics, we create a filtering handler on the fly.
-	filteredHandler, err := h.innerHandler(*filters...)
+	filteredHandler, err := h.innerHandler(options, *filters...)
 	if err != nil {
 		h.logger.Warn("Couldn't create filtered metrics handler:", "err", err)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "Couldn't create filtered metrics handler: %s", err)
 		return
 	}
-	filteredHandler.ServeHTTP(w, r)
+	defer filteredHandler.collector.Close()
+	filteredHandler.handler.ServeHTTP(w, r)
+}
+
+// collectorOptions returns the per-request collector options of a query,
+// given as <collector>.<option>=<value> parameters. Parameters naming no
+// option which may be set per request are rejected, rather than ignored.
+func collectorOptions(query url.Values) (collector.Options, error) {
+	var options collector.Options
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		values := query[key]
+		name, option, ok := strings.Cut(key, ".")
+		if !ok {
+			continue
+		}
+		if !collector.IsRequestOption(name, option) {
+			return nil, fmt.Errorf("unknown collector option %q", key)
+		}
+		if options == nil {
+			options = make(collector.Options)
+		}
+		if options[name] == nil {
+			options[name] = make(map[string][]string)
+		}
+		options[name][option] = values
+	}
+	return options, nil
 }
 
 // innerHandler is used to create both the one unfiltered http.Handler to be
-// wrapped by the outer handler and also the filtered handlers created on the
-// fly. The former is accomplished by calling innerHandler without any arguments
-// (in which case it will log all the collectors enabled via command-line
-// flags).
-func (h *handler) innerHandler(filters ...string) (http.Handler, error) {
-	nc, err := collector.NewNodeCollector(h.logger, filters...)
+// wrapped by the outer handler, see unfilteredInnerHandler, and also the
+// filtered handlers created on the fly. The collector of the returned handler
+// must be closed once the handler isn't used anymore.
+func (h *handler) innerHandler(options collector.Options, filters ...string) (prebuiltHandler, error) {
+	nc, err := collector.NewNodeCollectorWithOptions(h.logger, options, filters...)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't create collector: %s", err)
+		return prebuiltHandler{}, fmt.Errorf("couldn't create collector: %s", err)
 	}
+	return prebuiltHandler{handler: h.metricsHandler(nc), collector: nc}, nil
+}
 
-	// Only log the creation of an unfiltered handler, which should happen
-	// only once upon startup.
-	if len(filters) == 0 {
-		h.logger.Info("Enabled collectors")
-		for n := range nc.Collectors {
-			h.enabledCollectors = append(h.enabledCollectors, n)
-		}
-		sort.Strings(h.enabledCollectors)
-		for _, c := range h.enabledCollectors {
-			h.logger.Info(c)
+// unfilteredInnerHandler creates the unfiltered handler, upon startup and
+// configuration reloads, and logs all the collectors enabled via command-line
+// flags or the configuration file. Callers after startup must hold h.mtx, as
+// it updates h.enabledCollectors.
+func (h *handler) unfilteredInnerHandler() (prebuiltHandler, error) {
+	p, err := h.innerHandler(nil)
+	if err != nil {
+		return prebuiltHandler{}, err
+	}
+	h.logger.Info("Enabled collectors")
+	h.enabledCollectors = make([]string, 0, len(p.collector.Collectors))
+	for n := range p.collector.Collectors {
+		h.enabledCollectors = append(h.enabledCollectors, n)
+	}
+	sort.Strings(h.enabledCollectors)
+	for _, c := range h.enabledCollectors {
+		h.logger.Info(c)
+	}
+	return p, nil
+}
+
+// ServeProbe serves the metrics of the target named by the target query
+// parameter.
+func (h *handler) ServeProbe(w http.ResponseWriter, r *http.Request) {
+	r, cancel, ok := h.withScrapeTimeout(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	name := r.URL.Query().Get("target")
+	h.logger.Debug("target query:", "target", name)
+	if name == "" {
+		http.Error(w, "Target parameter is missing", http.StatusBadRequest)
+		return
+	}
+
+	h.mtx.RLock()
+	t, ok := h.targets[name]
+	h.mtx.RUnlock()
+	if !ok {
+		h.logger.Debug("rejecting unknown target", "target", name)
+		http.Error(w, fmt.Sprintf("Unknown target: %q", name), http.StatusBadRequest)
+		return
+	}
+	t.handler.ServeHTTP(w, r)
+}
+
+// prebuiltHandler is the handler of a scrape profile or target together with
+// the node collector backing it.
+type prebuiltHandler struct {
+	handler   http.Handler
+	collector *collector.NodeCollector
+}
+
+// closeHandlers closes the collectors of the given pre-built handlers.
+func closeHandlers(handlers map[string]prebuiltHandler) {
+	for _, p := range handlers {
+		p.collector.Close()
+	}
+}
+
+// newProfiles builds the handlers of the given scrape profiles. If an error
+// is returned, the collectors of all profiles are closed.
+func (h *handler) newProfiles(configs map[string]profileConfig) (map[string]prebuiltHandler, error) {
+	profiles := make(map[string]prebuiltHandler, len(configs))
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		nc, err := collector.NewProfileCollector(h.logger.With("profile", name), configs[name].Collectors)
+		if err != nil {
+			closeHandlers(profiles)
+			return nil, fmt.Errorf("couldn't create collector for profile %s: %w", name, err)
 		}
+		h.logger.Info("Enabled profile", "profile", name, "collectors", slices.Sorted(maps.Keys(nc.Collectors)))
+		profiles[name] = prebuiltHandler{handler: h.metricsHandler(nc), collector: nc}
 	}
+	return profiles, nil
+}
 
-	r := prometheus.NewRegistry()
-	r.MustRegister(versioncollector.NewCollector("node_exporter"))
-	if err := r.Register(nc); err != nil {
-		return nil, fmt.Errorf("couldn't register node collector: %s", err)
+// newTargets builds the handlers of the given /probe targets. If an error is
+// returned, the collectors of all targets are closed.
+func (h *handler) newTargets(configs map[string]targetConfig) (map[string]prebuiltHandler, error) {
+	targets := make(map[string]prebuiltHandler, len(configs))
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		cfg := configs[name]
+		nc, err := collector.NewTargetCollector(h.logger.With("target", name), cfg.Paths, cfg.Collectors)
+		if err != nil {
+			closeHandlers(targets)
+			return nil, fmt.Errorf("couldn't create collector for target %s: %w", name, err)
+		}
+		h.logger.Info("Enabled target", "target", name, "collectors", slices.Sorted(maps.Keys(nc.Collectors)))
+		targets[name] = prebuiltHandler{handler: h.metricsHandler(nc), collector: nc}
 	}
+	return targets, nil
+}
+
+// metricsHandler returns the http.Handler serving the metrics of nc.
+func (h *handler) metricsHandler(nc *collector.NodeCollector) http.Handler {
+	// The registry is created per request so that the scrape's context,
+	// including its timeout, reaches the node collector.
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if h.inFlightSem != nil {
+			select {
+			case h.inFlightSem <- struct{}{}:
+				defer func() { <-h.inFlightSem }()
+			default:
+				http.Error(w, fmt.Sprintf(
+					"Limit of concurrent requests reached (%d), try again later.", h.maxRequests,
+				), http.StatusServiceUnavailable)
+				return
+			}
+		}
+
+		r := prometheus.NewRegistry()
+		reg := h.wrapRegisterer(r)
+		reg.MustRegister(versioncollector.NewCollector("node_exporter"))
+		if h.reloadable() {
+			reg.MustRegister(configReloadSuccess, configReloadSeconds)
+		}
+		reg.MustRegister(h.collectors...)
+		if err := reg.Register(nc.WithContext(req.Context())); err != nil {
+			h.logger.Error("Couldn't register node collector", "err", err)
+			http.Error(w, fmt.Sprintf("Couldn't register node collector: %s", err), http.StatusInternalServerError)
+			return
+		}
 
-	var handler http.Handler
+		opts := promhttp.HandlerOpts{
+			ErrorLog:      slog.NewLogLogger(h.logger.Handler(), slog.LevelError),
+			ErrorHandling: promhttp.ContinueOnError,
+		}
+		if h.includeExporterMetrics {
+			// Note that we have to use h.exporterMetricsRegistry here to
+			// use the same promhttp metrics for all expositions.
+			opts.Registry = h.exporterMetricsRegistry
+			promhttp.HandlerFor(prometheus.Gatherers{h.labelGatherer(h.exporterMetricsRegistry), r}, opts).ServeHTTP(w, req)
+		} else {
+			promhttp.HandlerFor(r, opts).ServeHTTP(w, req)
+		}
+	})
 	if h.includeExporterMetrics {
-		handler = promhttp.HandlerFor(
-			prometheus.Gatherers{h.exporterMetricsRegistry, r},
-			promhttp.HandlerOpts{
-				ErrorLog:            slog.NewLogLogger(h.logger.Handler(), slog.LevelError),
-				ErrorHandling:       promhttp.ContinueOnError,
-				MaxRequestsInFlight: h.maxRequests,
-				Registry:            h.exporterMetricsRegistry,
-			},
-		)
-		// Note that we have to use h.exporterMetricsRegistry here to
-		// use the same promhttp metrics for all expositions.
 		handler = promhttp.InstrumentMetricHandler(
 			h.exporterMetricsRegistry, handler,
 		)
-	} else {
-		handler = promhttp.HandlerFor(
-			r,
-			promhttp.HandlerOpts{
-				ErrorLog:            slog.NewLogLogger(h.logger.Handler(), slog.LevelError),
-				ErrorHandling:       promhttp.ContinueOnError,
-				MaxRequestsInFlight: h.maxRequests,
-			},
-		)
 	}
 
-	return handler, nil
+	return handler
+}
+
+// wrapRegisterer returns a Registerer adding the current target labels to
+// the metrics of all collectors registered with r.
+func (h *handler) wrapRegisterer(r prometheus.Registerer) prometheus.Registerer {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	return prometheus.WrapRegistererWith(h.labels, r)
+}
+
+// labelGatherer returns a Gatherer adding the current target labels to the
+// metrics of g, for the registries whose collectors aren't registered through
+// wrapRegisterer, like exporterMetricsRegistry which is shared by all
+// scrapes.
+func (h *handler) labelGatherer(g prometheus.Gatherer) prometheus.Gatherer {
+	h.mtx.RLock()
+	labels := h.labels
+	h.mtx.RUnlock()
+	if len(labels) == 0 {
+		return g
+	}
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		mfs, err := g.Gather()
+		for _, mf := range mfs {
+			for _, m := range mf.Metric {
+				for name, value := range labels {
+					m.Label = append(m.Label, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)})
+				}
+				slices.SortFunc(m.Label, func(a, b *dto.LabelPair) int {
+					return strings.Compare(a.GetName(), b.GetName())
+				})
+			}
+		}
+		return mfs, err
+	})
+}
+
+// withScrapeTimeout bounds the context of r by the scrape timeout, see
+// scrapeTimeout. If the timeout header is invalid, it responds with an error
+// and returns false.
+func (h *handler) withScrapeTimeout(w http.ResponseWriter, r *http.Request) (*http.Request, context.CancelFunc, bool) {
+	timeout, err := h.scrapeTimeout(r)
+	if err != nil {
+		h.logger.Debug("rejecting invalid scrape timeout header", "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Invalid scrape timeout header: %s", err)
+		return nil, nil, false
+	}
+	if timeout <= 0 {
+		return r, func() {}, true
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return r.WithContext(ctx), cancel, true
+}
+
+// scrapeTimeout returns the scrape timeout announced by Prometheus in the
+// X-Prometheus-Scrape-Timeout-Seconds header, reduced by the configured
+// offset. It returns 0 if the header is absent.
+func (h *handler) scrapeTimeout(r *http.Request) (time.Duration, error) {
+	v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if v == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+	if seconds <= 0 {
+		return 0, fmt.Errorf("timeout must be positive, got %s", v)
+	}
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > h.scrapeTimeoutOffset {
+		timeout -= h.scrapeTimeoutOffset
+	}
+	return timeout, nil
 }
 
 func main() {
@@ -193,6 +488,10 @@ func main() {
 			"web.max-requests",
 			"Maximum number of parallel scrape requests. Use 0 to disable.",
 		).Default("40").Int()
+		scrapeTimeoutOffset = kingpin.Flag(
+			"web.scrape-timeout-offset",
+			"Offset to subtract from the timeout announced by Prometheus in the X-Prometheus-Scrape-Timeout-Seconds header.",
+		).Default("500ms").Duration()
 		disableDefaultCollectors = kingpin.Flag(
 			"collector.disable-defaults",
 			"Set all collectors to disabled by default.",
@@ -200,20 +499,85 @@ func main() {
 		maxProcs = kingpin.Flag(
 			"runtime.gomaxprocs", "The target number of CPUs Go will run on (GOMAXPROCS)",
 		).Envar("GOMAXPROCS").Default("1").Int()
+		configFile = kingpin.Flag(
+			"config.file",
+			"Path to a configuration file for the collectors. It is reloaded on SIGHUP or on a POST to /-/reload.",
+		).String()
+		targetLabelFlags = kingpin.Flag(
+			"target.label",
+			"Constant label to add to all series, as <name>=<value> (repeatable).",
+		).PlaceHolder("<name>=<value>").StringMap()
+		targetLabelFiles = kingpin.Flag(
+			"target.label-file",
+			"File of <name>=<value> lines with constant labels to add to all series (repeatable). Re-read on SIGHUP or on a POST to /-/reload.",
+		).Strings()
+		otlpEndpoint = kingpin.Flag(
+			"otlp.endpoint",
+			"OTLP/HTTP metrics endpoint to push metrics to, e.g. http://localhost:4318/v1/metrics. Pushing is disabled if empty.",
+		).String()
+		otlpInterval = kingpin.Flag(
+			"otlp.interval",
+			"Interval at which metrics are pushed to the OTLP endpoint.",
+		).Default("1m").Duration()
+		otlpTimeout = kingpin.Flag(
+			"otlp.timeout",
+			"Timeout for gathering and pushing metrics to the OTLP endpoint.",
+		).Default("10s").Duration()
+		otlpHeaders = kingpin.Flag(
+			"otlp.header",
+			"Header to send with OTLP requests, as <name>=<value> (repeatable).",
+		).PlaceHolder("<name>=<value>").StringMap()
+		remoteWriteURL = kingpin.Flag(
+			"remote-write.url",
+			"Prometheus remote write endpoint to send metrics to. Remote write is disabled if empty.",
+		).String()
+		remoteWriteInterval = kingpin.Flag(
+			"remote-write.interval",
+			"Interval at which metrics are gathered and sent to the remote write endpoint.",
+		).Default("15s").Duration()
+		remoteWriteTimeout = kingpin.Flag(
+			"remote-write.timeout",
+			"Timeout for gathering metrics and for each remote write request.",
+		).Default("10s").Duration()
+		remoteWriteBufferDir = kingpin.Flag(
+			"remote-write.buffer-dir",
+			"Directory to buffer unsent remote write batches in, so they survive restarts. Batches are buffered in memory if empty.",
+		).String()
+		remoteWriteBufferMaxAge = kingpin.Flag(
+			"remote-write.buffer-max-age",
+			"Maximum time unsent remote write batches are kept and retried for.",
+		).Default("1h").Duration()
+		remoteWriteHeaders = kingpin.Flag(
+			"remote-write.header",
+			"Header to send with remote write requests, as <name>=<value> (repeatable).",
+		).PlaceHolder("<name>=<value>").StringMap()
 		toolkitFlags = kingpinflag.AddFlags(kingpin.CommandLine, ":9100")
+
+		collectCommand = kingpin.Command("collect", "Run the collectors once and print their metrics to standard output. Exits with 1 if a collector failed.")
+		collectFormat  = collectCommand.Flag("format", "Output format.").Default("text").Enum("text", "json")
+		collectFilters = collectCommand.Arg("collectors", "Collectors to run, defaults to the enabled ones.").Strings()
 	)
+	kingpin.Command("serve", "Serve metrics over HTTP (default).").Default()
 
 	promslogConfig := &promslog.Config{}
 	flag.AddFlags(kingpin.CommandLine, promslogConfig)
 	kingpin.Version(version.Print("node_exporter"))
 	kingpin.CommandLine.UsageWriter(os.Stdout)
 	kingpin.HelpFlag.Short('h')
-	kingpin.Parse()
+	command := kingpin.Parse()
 	logger := promslog.New(promslogConfig)
 
 	if *disableDefaultCollectors {
 		collector.DisableDefaultCollectors()
 	}
+	cliLabels := labelsConfig{Labels: *targetLabelFlags, Files: *targetLabelFiles}
+	if command == collectCommand.FullCommand() {
+		if err := runCollect(os.Stdout, *collectFormat, *configFile, cliLabels, *collectFilters, logger); err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 	logger.Info("Starting node_exporter", "version", version.Info())
 	logger.Info("Build context", "build_context", version.BuildContext())
 	if user, err := user.Current(); err == nil && user.Uid == "0" {
@@ -222,7 +586,68 @@ func main() {
 	runtime.GOMAXPROCS(*maxProcs)
 	logger.Debug("Go MAXPROCS", "procs", runtime.GOMAXPROCS(0))
 
-	http.Handle(*metricsPath, newHandler(!*disableExporterMetrics, *maxRequests, logger))
+	h, err := newHandler(!*disableExporterMetrics, *maxRequests, *scrapeTimeoutOffset, *configFile, cliLabels, logger)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	http.Handle(*metricsPath, h)
+	http.HandleFunc("/probe", h.ServeProbe)
+	http.Handle("/collectors", collectorsHandler(logger))
+	if *remoteWriteURL != "" {
+		h.collectors = append(h.collectors, remoteWriteMetrics...)
+		w := &remoteWriter{
+			url:      *remoteWriteURL,
+			headers:  *remoteWriteHeaders,
+			interval: *remoteWriteInterval,
+			timeout:  *remoteWriteTimeout,
+			maxAge:   *remoteWriteBufferMaxAge,
+			dir:      *remoteWriteBufferDir,
+			gather:   h.newNodeGatherer(),
+			client:   &http.Client{},
+			logger:   logger,
+		}
+		if err := w.loadBuffer(); err != nil {
+			logger.Error("Couldn't load remote write buffer", "dir", *remoteWriteBufferDir, "err", err)
+			os.Exit(1)
+		}
+		logger.Info("Sending metrics to remote write endpoint", "url", *remoteWriteURL, "interval", *remoteWriteInterval)
+		go w.run(context.Background())
+	}
+	if *otlpEndpoint != "" {
+		h.collectors = append(h.collectors, otlpExports)
+		e := &otlpExporter{
+			endpoint: *otlpEndpoint,
+			headers:  *otlpHeaders,
+			interval: *otlpInterval,
+			timeout:  *otlpTimeout,
+			gather:   h.newNodeGatherer(),
+			client:   &http.Client{},
+			logger:   logger,
+			start:    time.Now(),
+		}
+		logger.Info("Pushing metrics to OTLP endpoint", "endpoint", *otlpEndpoint, "interval", *otlpInterval)
+		go e.run(context.Background())
+	}
+	if h.reloadable() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				h.reloadConfig()
+			}
+		}()
+		http.HandleFunc("/-/reload", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost && r.Method != http.MethodPut {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				fmt.Fprintf(w, "This endpoint requires a POST or PUT request.\n")
+				return
+			}
+			if err := h.reloadConfig(); err != nil {
+				http.Error(w, fmt.Sprintf("Failed to reload config: %s", err), http.StatusInternalServerError)
+			}
+		})
+	}
 	if *metricsPath != "/" {
 		landingConfig := web.LandingConfig{
 			Name:        "Node Exporter",
@@ -233,6 +658,10 @@ func main() {
 					Address: *metricsPath,
 					Text:    "Metrics",
 				},
+				{
+					Address: "/collectors",
+					Text:    "Collectors",
+				},
 			},
 		}
 		landingPage, err := web.NewLandingPage(landingConfig)
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -34,6 +35,21 @@ const (
 	address = "localhost:19100"
 )
 
+func TestCollectorOptions(t *testing.T) {
+	if options, err := collectorOptions(url.Values{"collect[]": {"cpu"}}); err != nil || options != nil {
+		t.Errorf("want no options, got %v (err %v)", options, err)
+	}
+	for _, query := range []string{"utm.source=mail", "cpu.unknown=1", "netdev.device-exclude=^lo$&netdev.typo=1"} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := collectorOptions(values); err == nil {
+			t.Errorf("%s: expected error", query)
+		}
+	}
+}
+
 func TestFileDescriptorLeak(t *testing.T) {
 	if _, err := os.Stat(binary); err != nil {
 		t.Skipf("node_exporter binary not available, try to run `make build` first: %s", err)
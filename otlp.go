@@ -0,0 +1,435 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/version"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/node_exporter/collector"
+)
+
+var otlpExports = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "node_exporter",
+	Name:      "otlp_exports_total",
+	Help:      "Number of OTLP exports by result.",
+}, []string{"result"})
+
+// hostIdentities are the host identity metrics whose labels become OTLP
+// resource attributes. Labels with an equivalent in the OpenTelemetry
+// semantic conventions are renamed to it, the others are prefixed.
+var hostIdentities = map[string]struct {
+	prefix  string
+	semconv map[string]string
+}{
+	"node_uname_info": {"node.uname.", map[string]string{
+		"nodename": "host.name",
+		"machine":  "host.arch",
+		"sysname":  "os.type",
+	}},
+	"node_os_info": {"node.os.", map[string]string{
+		"name":        "os.name",
+		"version_id":  "os.version",
+		"pretty_name": "os.description",
+		"build_id":    "os.build_id",
+	}},
+	"node_dmi_info": {"node.dmi.", map[string]string{
+		"product_name": "host.type",
+	}},
+}
+
+// otlpExporter periodically pushes the metrics of gather to an OTLP/HTTP
+// endpoint, encoded as protobuf.
+type otlpExporter struct {
+	endpoint string
+	headers  map[string]string
+	interval time.Duration
+	timeout  time.Duration
+	gather   func(ctx context.Context) ([]*dto.MetricFamily, error)
+	client   *http.Client
+	logger   *slog.Logger
+	// start is reported as the start time of cumulative metrics without
+	// a created timestamp.
+	start time.Time
+}
+
+// newNodeGatherer returns a function gathering the metrics of the enabled
+// collectors, as served on the unfiltered metrics endpoint.
+func (h *handler) newNodeGatherer() func(ctx context.Context) ([]*dto.MetricFamily, error) {
+	return func(ctx context.Context) ([]*dto.MetricFamily, error) {
+		nc, err := collector.NewNodeCollector(h.logger)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create collector: %w", err)
+		}
+		r := prometheus.NewRegistry()
+		reg := h.wrapRegisterer(r)
+		reg.MustRegister(h.collectors...)
+		if err := reg.Register(nc.WithContext(ctx)); err != nil {
+			return nil, fmt.Errorf("couldn't register node collector: %w", err)
+		}
+		if !h.includeExporterMetrics {
+			return r.Gather()
+		}
+		return prometheus.Gatherers{h.labelGatherer(h.exporterMetricsRegistry), r}.Gather()
+	}
+}
+
+func (e *otlpExporter) run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		if err := e.export(ctx); err != nil {
+			e.logger.Error("OTLP export failed", "endpoint", e.endpoint, "err", err)
+			otlpExports.WithLabelValues("failure").Inc()
+		} else {
+			otlpExports.WithLabelValues("success").Inc()
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// export gathers the metrics once and pushes them.
+func (e *otlpExporter) export(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	mfs, err := e.gather(ctx)
+	if err != nil {
+		// Like the metrics endpoint, push whatever was gathered.
+		e.logger.Warn("Error gathering metrics for OTLP export", "err", err)
+	}
+	body, err := proto.Marshal(toMetricsData(mfs, time.Now(), e.start, e.logger))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "node_exporter/"+version.Version)
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("server returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
+
+// toMetricsData converts metric families to OTLP. MetricsData is wire
+// compatible with the ExportMetricsServiceRequest expected by OTLP receivers.
+func toMetricsData(mfs []*dto.MetricFamily, now, start time.Time, logger *slog.Logger) *metricspb.MetricsData {
+	scope := &metricspb.ScopeMetrics{
+		Scope: &commonpb.InstrumentationScope{
+			Name:    "github.com/prometheus/node_exporter",
+			Version: version.Version,
+		},
+	}
+	for _, mf := range mfs {
+		if m := toMetric(mf, now, start, logger); m != nil {
+			scope.Metrics = append(scope.Metrics, m)
+		}
+	}
+	return &metricspb.MetricsData{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource:     &resourcepb.Resource{Attributes: resourceAttributes(mfs)},
+			ScopeMetrics: []*metricspb.ScopeMetrics{scope},
+		}},
+	}
+}
+
+// resourceAttributes derives the resource attributes from the host identity
+// metrics among mfs.
+func resourceAttributes(mfs []*dto.MetricFamily) []*commonpb.KeyValue {
+	attrs := []*commonpb.KeyValue{
+		stringAttribute("service.name", "node_exporter"),
+		stringAttribute("service.version", version.Version),
+	}
+	for _, mf := range mfs {
+		identity, ok := hostIdentities[mf.GetName()]
+		if !ok || len(mf.GetMetric()) == 0 {
+			continue
+		}
+		for _, lp := range mf.GetMetric()[0].GetLabel() {
+			if lp.GetValue() == "" {
+				continue
+			}
+			key, value := identity.prefix+lp.GetName(), lp.GetValue()
+			if k, ok := identity.semconv[lp.GetName()]; ok {
+				key = k
+			}
+			if key == "os.type" {
+				value = strings.ToLower(value)
+			}
+			attrs = append(attrs, stringAttribute(key, value))
+		}
+	}
+	return attrs
+}
+
+func toMetric(mf *dto.MetricFamily, now, start time.Time, logger *slog.Logger) *metricspb.Metric {
+	m := &metricspb.Metric{
+		Name:        mf.GetName(),
+		Description: mf.GetHelp(),
+		Unit:        mf.GetUnit(),
+	}
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER:
+		sum := &metricspb.Sum{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}
+		for _, pm := range mf.GetMetric() {
+			dp := numberDataPoint(pm, pm.GetCounter().GetValue(), now)
+			dp.StartTimeUnixNano = startTime(pm.GetCounter().GetCreatedTimestamp().AsTime(), start)
+			sum.DataPoints = append(sum.DataPoints, dp)
+		}
+		m.Data = &metricspb.Metric_Sum{Sum: sum}
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		gauge := &metricspb.Gauge{}
+		for _, pm := range mf.GetMetric() {
+			v := pm.GetGauge().GetValue()
+			if mf.GetType() == dto.MetricType_UNTYPED {
+				v = pm.GetUntyped().GetValue()
+			}
+			gauge.DataPoints = append(gauge.DataPoints, numberDataPoint(pm, v, now))
+		}
+		m.Data = &metricspb.Metric_Gauge{Gauge: gauge}
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		// The buckets of gauge histograms can go down, unlike cumulative
+		// counts, so they are exported as deltas.
+		temporality := metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+		if mf.GetType() == dto.MetricType_GAUGE_HISTOGRAM {
+			temporality = metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+		}
+		// The bounds of native histograms with custom buckets aren't in the
+		// client model, so they can't be converted.
+		metrics := slices.DeleteFunc(slices.Clone(mf.GetMetric()), isCustomBucketsHistogram)
+		if skipped := len(mf.GetMetric()) - len(metrics); skipped > 0 {
+			logger.Warn("Skipping native histograms with custom buckets in OTLP export", "metric", mf.GetName(), "count", skipped)
+			if len(metrics) == 0 {
+				return nil
+			}
+		}
+		if slices.ContainsFunc(metrics, isNativeHistogram) {
+			m.Data = &metricspb.Metric_ExponentialHistogram{ExponentialHistogram: exponentialHistogram(metrics, temporality, now, start)}
+			break
+		}
+		hist := &metricspb.Histogram{AggregationTemporality: temporality}
+		for _, pm := range metrics {
+			hist.DataPoints = append(hist.DataPoints, histogramDataPoint(pm, now, start))
+		}
+		m.Data = &metricspb.Metric_Histogram{Histogram: hist}
+	case dto.MetricType_SUMMARY:
+		summary := &metricspb.Summary{}
+		for _, pm := range mf.GetMetric() {
+			s := pm.GetSummary()
+			dp := &metricspb.SummaryDataPoint{
+				Attributes:        attributes(pm),
+				StartTimeUnixNano: startTime(s.GetCreatedTimestamp().AsTime(), start),
+				TimeUnixNano:      timestamp(pm, now),
+				Count:             s.GetSampleCount(),
+				Sum:               s.GetSampleSum(),
+			}
+			for _, q := range s.GetQuantile() {
+				dp.QuantileValues = append(dp.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{
+					Quantile: q.GetQuantile(),
+					Value:    q.GetValue(),
+				})
+			}
+			summary.DataPoints = append(summary.DataPoints, dp)
+		}
+		m.Data = &metricspb.Metric_Summary{Summary: summary}
+	default:
+		return nil
+	}
+	return m
+}
+
+// customBucketsSchema is the schema of native histograms with custom bucket
+// bounds instead of exponential ones.
+const customBucketsSchema = -53
+
+// histogramDataPoint converts a classic histogram to a data point with
+// explicit bounds.
+func histogramDataPoint(pm *dto.Metric, now, start time.Time) *metricspb.HistogramDataPoint {
+	h := pm.GetHistogram()
+	dp := &metricspb.HistogramDataPoint{
+		Attributes:        attributes(pm),
+		StartTimeUnixNano: startTime(h.GetCreatedTimestamp().AsTime(), start),
+		TimeUnixNano:      timestamp(pm, now),
+		Count:             h.GetSampleCount(),
+		Sum:               proto.Float64(h.GetSampleSum()),
+	}
+	// Prometheus buckets are cumulative, OTLP ones are not, and the +Inf
+	// bucket is implied by the count.
+	var previous uint64
+	for _, b := range h.GetBucket() {
+		if math.IsInf(b.GetUpperBound(), +1) {
+			continue
+		}
+		dp.ExplicitBounds = append(dp.ExplicitBounds, b.GetUpperBound())
+		dp.BucketCounts = append(dp.BucketCounts, b.GetCumulativeCount()-previous)
+		previous = b.GetCumulativeCount()
+	}
+	dp.BucketCounts = append(dp.BucketCounts, h.GetSampleCount()-previous)
+	return dp
+}
+
+// isNativeHistogram returns whether pm is a native histogram, having
+// exponential or custom buckets or a zero bucket. Empty native histograms
+// have a single span of no buckets.
+func isNativeHistogram(pm *dto.Metric) bool {
+	h := pm.GetHistogram()
+	return h.GetZeroThreshold() > 0 || h.GetZeroCount() > 0 || h.GetZeroCountFloat() > 0 ||
+		len(h.GetPositiveSpan()) > 0 || len(h.GetNegativeSpan()) > 0
+}
+
+// isCustomBucketsHistogram returns whether pm is a native histogram with
+// custom bucket bounds.
+func isCustomBucketsHistogram(pm *dto.Metric) bool {
+	return isNativeHistogram(pm) && pm.GetHistogram().GetSchema() == customBucketsSchema
+}
+
+// exponentialHistogram converts the native histograms among metrics, whose
+// schema is the OTLP scale. Their classic buckets, and the ones of the
+// histograms which aren't native, aren't converted, as a metric has only one
+// type in OTLP.
+func exponentialHistogram(metrics []*dto.Metric, temporality metricspb.AggregationTemporality, now, start time.Time) *metricspb.ExponentialHistogram {
+	hist := &metricspb.ExponentialHistogram{AggregationTemporality: temporality}
+	for _, pm := range metrics {
+		h := pm.GetHistogram()
+		dp := &metricspb.ExponentialHistogramDataPoint{
+			Attributes:        attributes(pm),
+			StartTimeUnixNano: startTime(h.GetCreatedTimestamp().AsTime(), start),
+			TimeUnixNano:      timestamp(pm, now),
+			Count:             h.GetSampleCount(),
+			Sum:               proto.Float64(h.GetSampleSum()),
+			Scale:             h.GetSchema(),
+			ZeroCount:         h.GetZeroCount(),
+			ZeroThreshold:     h.GetZeroThreshold(),
+			Positive:          exponentialBuckets(h.GetPositiveSpan(), h.GetPositiveDelta(), h.GetPositiveCount()),
+			Negative:          exponentialBuckets(h.GetNegativeSpan(), h.GetNegativeDelta(), h.GetNegativeCount()),
+		}
+		if h.SampleCountFloat != nil {
+			dp.Count = uint64(h.GetSampleCountFloat())
+			dp.ZeroCount = uint64(h.GetZeroCountFloat())
+		}
+		hist.DataPoints = append(hist.DataPoints, dp)
+	}
+	return hist
+}
+
+// exponentialBuckets converts the sparse buckets of a native histogram,
+// given by spans and either delta-encoded integer counts or float counts, to
+// dense OTLP buckets. The Prometheus bucket of index i has the upper bound
+// base^i, the OTLP one the lower bound, hence the offset by one.
+func exponentialBuckets(spans []*dto.BucketSpan, deltas []int64, counts []float64) *metricspb.ExponentialHistogramDataPoint_Buckets {
+	if len(spans) == 0 {
+		return nil
+	}
+	b := &metricspb.ExponentialHistogramDataPoint_Buckets{Offset: spans[0].GetOffset() - 1}
+	var (
+		i     int
+		count int64
+	)
+	for n, span := range spans {
+		if n > 0 {
+			// Buckets between spans are empty.
+			for range span.GetOffset() {
+				b.BucketCounts = append(b.BucketCounts, 0)
+			}
+		}
+		for range span.GetLength() {
+			switch {
+			case i < len(deltas):
+				count += deltas[i]
+				b.BucketCounts = append(b.BucketCounts, uint64(count))
+			case i < len(counts):
+				b.BucketCounts = append(b.BucketCounts, uint64(counts[i]))
+			}
+			i++
+		}
+	}
+	return b
+}
+
+func numberDataPoint(pm *dto.Metric, v float64, now time.Time) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{
+		Attributes:   attributes(pm),
+		TimeUnixNano: timestamp(pm, now),
+		Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: v},
+	}
+}
+
+func attributes(pm *dto.Metric) []*commonpb.KeyValue {
+	attrs := make([]*commonpb.KeyValue, 0, len(pm.GetLabel()))
+	for _, lp := range pm.GetLabel() {
+		attrs = append(attrs, stringAttribute(lp.GetName(), lp.GetValue()))
+	}
+	return attrs
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// timestamp returns the time of pm in nanoseconds, defaulting to now.
+func timestamp(pm *dto.Metric, now time.Time) uint64 {
+	if pm.TimestampMs != nil {
+		return uint64(pm.GetTimestampMs()) * uint64(time.Millisecond)
+	}
+	return uint64(now.UnixNano())
+}
+
+// startTime returns created in nanoseconds, defaulting to start if it is
+// unset.
+func startTime(created, start time.Time) uint64 {
+	if created.Unix() <= 0 {
+		created = start
+	}
+	return uint64(created.UnixNano())
+}
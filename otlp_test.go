@@ -0,0 +1,213 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPExport(t *testing.T) {
+	received := make(chan *metricspb.MetricsData, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/x-protobuf" {
+			t.Errorf("unexpected content type %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected authorization header %q", got)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		md := &metricspb.MetricsData{}
+		if err := proto.Unmarshal(body, md); err != nil {
+			t.Errorf("couldn't decode request: %s", err)
+		}
+		received <- md
+	}))
+	defer receiver.Close()
+
+	reg := prometheus.NewRegistry()
+	uname := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "node_uname_info", Help: "uname"}, []string{"machine", "nodename", "release", "sysname"})
+	uname.WithLabelValues("x86_64", "host1", "6.1.0", "Linux").Set(1)
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_total", Help: "counter"}, []string{"device"})
+	counter.WithLabelValues("eth0").Add(3)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Help: "histogram", Buckets: []float64{1, 5}})
+	for _, v := range []float64{0.5, 2, 3, 10} {
+		histogram.Observe(v)
+	}
+	native := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_native_seconds", Help: "native histogram", NativeHistogramBucketFactor: 2})
+	for _, v := range []float64{0.5, 2, 3, 10} {
+		native.Observe(v)
+	}
+	summary := prometheus.NewSummary(prometheus.SummaryOpts{Name: "test_summary", Help: "summary", Objectives: map[float64]float64{0.5: 0.05}})
+	summary.Observe(4)
+	reg.MustRegister(uname, counter, histogram, native, summary)
+
+	e := &otlpExporter{
+		endpoint: receiver.URL,
+		headers:  map[string]string{"Authorization": "Bearer secret"},
+		timeout:  time.Second,
+		gather:   func(context.Context) ([]*dto.MetricFamily, error) { return reg.Gather() },
+		client:   receiver.Client(),
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		start:    time.Now(),
+	}
+	if err := e.export(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	md := <-received
+
+	if len(md.ResourceMetrics) != 1 || len(md.ResourceMetrics[0].ScopeMetrics) != 1 {
+		t.Fatalf("want a single resource and scope, got %v", md)
+	}
+	attrs := map[string]string{}
+	for _, kv := range md.ResourceMetrics[0].Resource.Attributes {
+		attrs[kv.Key] = kv.Value.GetStringValue()
+	}
+	for k, v := range map[string]string{
+		"service.name":       "node_exporter",
+		"host.name":          "host1",
+		"host.arch":          "x86_64",
+		"os.type":            "linux",
+		"node.uname.release": "6.1.0",
+	} {
+		if attrs[k] != v {
+			t.Errorf("want resource attribute %s=%q, got %q", k, v, attrs[k])
+		}
+	}
+
+	metrics := map[string]*metricspb.Metric{}
+	for _, m := range md.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+
+	sum := metrics["test_total"].GetSum()
+	if sum == nil || !sum.IsMonotonic || sum.AggregationTemporality != metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+		t.Fatalf("want a cumulative monotonic sum for the counter, got %v", metrics["test_total"])
+	}
+	if dp := sum.DataPoints[0]; dp.GetAsDouble() != 3 || dp.Attributes[0].Key != "device" || dp.StartTimeUnixNano == 0 {
+		t.Errorf("unexpected counter data point %v", dp)
+	}
+
+	if g := metrics["node_uname_info"].GetGauge(); g == nil || g.DataPoints[0].GetAsDouble() != 1 {
+		t.Errorf("want a gauge for node_uname_info, got %v", metrics["node_uname_info"])
+	}
+
+	hist := metrics["test_seconds"].GetHistogram()
+	if hist == nil {
+		t.Fatalf("want a histogram, got %v", metrics["test_seconds"])
+	}
+	dp := hist.DataPoints[0]
+	if !slices.Equal(dp.ExplicitBounds, []float64{1, 5}) || !slices.Equal(dp.BucketCounts, []uint64{1, 2, 1}) || dp.Count != 4 || dp.GetSum() != 15.5 {
+		t.Errorf("unexpected histogram data point %v", dp)
+	}
+
+	exp := metrics["test_native_seconds"].GetExponentialHistogram()
+	if exp == nil {
+		t.Fatalf("want an exponential histogram, got %v", metrics["test_native_seconds"])
+	}
+	// With schema 0, the buckets are powers of 2, 0.5 being in (0.25, 0.5].
+	edp := exp.DataPoints[0]
+	if edp.Scale != 0 || edp.Count != 4 || edp.GetSum() != 15.5 || edp.Positive.GetOffset() != -2 ||
+		!slices.Equal(edp.Positive.GetBucketCounts(), []uint64{1, 0, 1, 1, 0, 1}) || edp.Negative.GetBucketCounts() != nil {
+		t.Errorf("unexpected exponential histogram data point %v", edp)
+	}
+
+	s := metrics["test_summary"].GetSummary()
+	if s == nil || s.DataPoints[0].Count != 1 || len(s.DataPoints[0].QuantileValues) != 1 || s.DataPoints[0].QuantileValues[0].Value != 4 {
+		t.Errorf("unexpected summary %v", metrics["test_summary"])
+	}
+}
+
+func TestOTLPExportFailure(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	e := &otlpExporter{
+		endpoint: receiver.URL,
+		timeout:  time.Second,
+		gather:   func(context.Context) ([]*dto.MetricFamily, error) { return nil, nil },
+		client:   receiver.Client(),
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := e.export(context.Background()); err == nil {
+		t.Error("expected error on HTTP 503")
+	}
+}
+
+func TestToMetricHistograms(t *testing.T) {
+	now := time.Now()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	gauge := &dto.MetricFamily{
+		Name: proto.String("test_queue_seconds"),
+		Type: dto.MetricType_GAUGE_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{Histogram: &dto.Histogram{
+			SampleCount: proto.Uint64(3),
+			SampleSum:   proto.Float64(4),
+			Bucket: []*dto.Bucket{
+				{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(2)},
+				{UpperBound: proto.Float64(math.Inf(+1)), CumulativeCount: proto.Uint64(3)},
+			},
+		}}},
+	}
+	hist := toMetric(gauge, now, now, logger).GetHistogram()
+	if hist == nil || hist.AggregationTemporality != metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA {
+		t.Fatalf("want a delta histogram for the gauge histogram, got %v", hist)
+	}
+	if dp := hist.DataPoints[0]; !slices.Equal(dp.ExplicitBounds, []float64{1}) || !slices.Equal(dp.BucketCounts, []uint64{2, 1}) || dp.Count != 3 {
+		t.Errorf("unexpected gauge histogram data point %v", dp)
+	}
+
+	// Native histograms with custom buckets are skipped, the others of the
+	// family are kept.
+	custom := &dto.MetricFamily{
+		Name: proto.String("test_custom_seconds"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{
+			{Histogram: &dto.Histogram{
+				SampleCount:   proto.Uint64(1),
+				Schema:        proto.Int32(customBucketsSchema),
+				PositiveSpan:  []*dto.BucketSpan{{Offset: proto.Int32(0), Length: proto.Uint32(1)}},
+				PositiveDelta: []int64{1},
+			}},
+		},
+	}
+	if m := toMetric(custom, now, now, logger); m != nil {
+		t.Errorf("want histograms with custom buckets skipped, got %v", m)
+	}
+	custom.Metric = append(custom.Metric, &dto.Metric{Histogram: &dto.Histogram{
+		SampleCount: proto.Uint64(1),
+		Bucket:      []*dto.Bucket{{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(1)}},
+	}})
+	if hist := toMetric(custom, now, now, logger).GetHistogram(); hist == nil || len(hist.DataPoints) != 1 {
+		t.Errorf("want the classic histogram of the family kept, got %v", hist)
+	}
+}
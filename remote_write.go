@@ -0,0 +1,415 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/version"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	remoteWriteMinBackoff = time.Second
+	remoteWriteMaxBackoff = time.Minute
+	// remoteWriteBatchSuffix is the file name suffix of batches buffered on
+	// disk, named <creation time in ns>-<number of samples><suffix>.
+	remoteWriteBatchSuffix = ".rw"
+)
+
+var (
+	remoteWriteSamplesSent = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "node",
+		Subsystem: "remote_write",
+		Name:      "samples_sent_total",
+		Help:      "Number of samples successfully sent to the remote write endpoint.",
+	})
+	remoteWriteSamplesDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "node",
+		Subsystem: "remote_write",
+		Name:      "samples_dropped_total",
+		Help:      "Number of samples dropped without being sent, by reason.",
+	}, []string{"reason"})
+	remoteWriteRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "node",
+		Subsystem: "remote_write",
+		Name:      "retries_total",
+		Help:      "Number of failed remote write requests which will be retried.",
+	})
+	remoteWritePendingSamples = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "node",
+		Subsystem: "remote_write",
+		Name:      "pending_samples",
+		Help:      "Number of samples buffered for sending.",
+	})
+	remoteWriteOldestPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "node",
+		Subsystem: "remote_write",
+		Name:      "oldest_pending_timestamp_seconds",
+		Help:      "Creation time of the oldest batch buffered for sending, 0 if there is none.",
+	})
+)
+
+// remoteWriteMetrics report on the remote write queue. They are served along
+// with the node collector's metrics.
+var remoteWriteMetrics = []prometheus.Collector{
+	remoteWriteSamplesSent,
+	remoteWriteSamplesDropped,
+	remoteWriteRetries,
+	remoteWritePendingSamples,
+	remoteWriteOldestPending,
+}
+
+// recoverableError is an error after which sending is retried.
+type recoverableError struct {
+	error
+}
+
+// remoteWriteBatch is a snappy-compressed remote write request. Batches
+// buffered on disk only hold their data while being sent.
+type remoteWriteBatch struct {
+	created time.Time
+	samples int
+	path    string
+	data    []byte
+}
+
+// remoteWriter gathers metrics on an interval and sends them with the
+// Prometheus remote write protocol. Batches which can't be sent are buffered,
+// in memory or in a directory to survive restarts, and retried with
+// exponential backoff until they are older than maxAge.
+type remoteWriter struct {
+	url      string
+	headers  map[string]string
+	interval time.Duration
+	timeout  time.Duration
+	maxAge   time.Duration
+	dir      string
+	gather   func(ctx context.Context) ([]*dto.MetricFamily, error)
+	client   *http.Client
+	logger   *slog.Logger
+
+	// pending are the buffered batches, oldest first.
+	pending []*remoteWriteBatch
+}
+
+// loadBuffer picks up the batches buffered on disk by a previous run.
+func (w *remoteWriter) loadBuffer() error {
+	if w.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(w.dir, 0o700); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), remoteWriteBatchSuffix)
+		if !ok || !e.Type().IsRegular() {
+			continue
+		}
+		created, samples, ok := strings.Cut(name, "-")
+		ns, err := strconv.ParseInt(created, 10, 64)
+		if !ok || err != nil {
+			w.logger.Warn("Ignoring unexpected file in remote write buffer", "file", e.Name())
+			continue
+		}
+		n, err := strconv.Atoi(samples)
+		if err != nil {
+			w.logger.Warn("Ignoring unexpected file in remote write buffer", "file", e.Name())
+			continue
+		}
+		w.pending = append(w.pending, &remoteWriteBatch{
+			created: time.Unix(0, ns),
+			samples: n,
+			path:    filepath.Join(w.dir, e.Name()),
+		})
+	}
+	sort.Slice(w.pending, func(i, j int) bool { return w.pending[i].created.Before(w.pending[j].created) })
+	w.updatePendingMetrics()
+	return nil
+}
+
+func (w *remoteWriter) run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var (
+		backoff = remoteWriteMinBackoff
+		retry   <-chan time.Time
+	)
+	w.enqueue(ctx)
+	for {
+		if retry == nil {
+			if err := w.flush(ctx); err != nil {
+				w.logger.Warn("Remote write failed, retrying", "url", w.url, "backoff", backoff, "err", err)
+				remoteWriteRetries.Inc()
+				retry = time.After(backoff)
+				backoff = min(2*backoff, remoteWriteMaxBackoff)
+			} else {
+				backoff = remoteWriteMinBackoff
+			}
+		}
+		select {
+		case <-ticker.C:
+			w.enqueue(ctx)
+		case <-retry:
+			retry = nil
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// enqueue gathers the metrics once and appends them to the pending batches.
+func (w *remoteWriter) enqueue(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	now := time.Now()
+	mfs, err := w.gather(ctx)
+	if err != nil {
+		// Like the metrics endpoint, send whatever was gathered.
+		w.logger.Warn("Error gathering metrics for remote write", "err", err)
+	}
+	request, samples := encodeWriteRequest(mfs, now)
+	if samples == 0 {
+		return
+	}
+	b := &remoteWriteBatch{
+		created: now,
+		samples: samples,
+		data:    snappy.Encode(nil, request),
+	}
+	if w.dir != "" {
+		b.path = filepath.Join(w.dir, fmt.Sprintf("%d-%d%s", now.UnixNano(), samples, remoteWriteBatchSuffix))
+		if err := os.WriteFile(b.path, b.data, 0o600); err != nil {
+			w.logger.Error("Couldn't buffer remote write batch on disk, keeping it in memory", "err", err)
+			b.path = ""
+		} else {
+			b.data = nil
+		}
+	}
+	w.pending = append(w.pending, b)
+	w.updatePendingMetrics()
+}
+
+// flush sends the pending batches in order. It stops at the first
+// recoverable error and returns it. Batches older than maxAge and batches
+// rejected by the endpoint are dropped.
+func (w *remoteWriter) flush(ctx context.Context) error {
+	defer w.updatePendingMetrics()
+	for len(w.pending) > 0 {
+		b := w.pending[0]
+		if time.Since(b.created) > w.maxAge {
+			w.logger.Warn("Dropping remote write batch exceeding the maximum age", "created", b.created, "samples", b.samples)
+			w.drop(b, "expired")
+			continue
+		}
+		data := b.data
+		if data == nil {
+			var err error
+			if data, err = os.ReadFile(b.path); err != nil {
+				w.logger.Error("Dropping unreadable remote write batch", "err", err)
+				w.drop(b, "unreadable")
+				continue
+			}
+		}
+		err := w.send(ctx, data)
+		var recoverable recoverableError
+		if errors.As(err, &recoverable) {
+			return err
+		}
+		if err != nil {
+			w.logger.Error("Dropping remote write batch rejected by the endpoint", "samples", b.samples, "err", err)
+			w.drop(b, "rejected")
+			continue
+		}
+		remoteWriteSamplesSent.Add(float64(b.samples))
+		w.remove(b)
+	}
+	return nil
+}
+
+func (w *remoteWriter) drop(b *remoteWriteBatch, reason string) {
+	remoteWriteSamplesDropped.WithLabelValues(reason).Add(float64(b.samples))
+	w.remove(b)
+}
+
+// remove removes the first pending batch, which must be b.
+func (w *remoteWriter) remove(b *remoteWriteBatch) {
+	w.pending = w.pending[1:]
+	if b.path != "" {
+		if err := os.Remove(b.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			w.logger.Error("Couldn't remove remote write batch from disk", "err", err)
+		}
+	}
+}
+
+func (w *remoteWriter) send(ctx context.Context, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "node_exporter/"+version.Version)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return recoverableError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("server returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return recoverableError{err}
+	}
+	return err
+}
+
+func (w *remoteWriter) updatePendingMetrics() {
+	var samples int
+	for _, b := range w.pending {
+		samples += b.samples
+	}
+	remoteWritePendingSamples.Set(float64(samples))
+	if len(w.pending) == 0 {
+		remoteWriteOldestPending.Set(0)
+		return
+	}
+	remoteWriteOldestPending.Set(float64(w.pending[0].created.UnixNano()) / 1e9)
+}
+
+// encodeWriteRequest encodes metric families as a remote write request:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+//
+// Histograms and summaries are split into their series as in the text
+// format. It returns the request and the number of samples in it.
+func encodeWriteRequest(mfs []*dto.MetricFamily, now time.Time) ([]byte, int) {
+	var (
+		buf     []byte
+		samples int
+	)
+	add := func(name string, pm *dto.Metric, v float64, extra ...string) {
+		labels := make([]string, 0, 2*len(pm.GetLabel())+2+len(extra))
+		labels = append(labels, "__name__", name)
+		for _, lp := range pm.GetLabel() {
+			labels = append(labels, lp.GetName(), lp.GetValue())
+		}
+		labels = append(labels, extra...)
+		ts := now.UnixMilli()
+		if pm.TimestampMs != nil {
+			ts = pm.GetTimestampMs()
+		}
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, encodeTimeSeries(labels, v, ts))
+		samples++
+	}
+
+	for _, mf := range mfs {
+		name := mf.GetName()
+		for _, pm := range mf.GetMetric() {
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, pm, pm.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, pm, pm.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, pm, pm.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				h := pm.GetHistogram()
+				infSeen := false
+				for _, b := range h.GetBucket() {
+					if math.IsInf(b.GetUpperBound(), +1) {
+						infSeen = true
+					}
+					add(name+"_bucket", pm, float64(b.GetCumulativeCount()), "le", strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64))
+				}
+				if !infSeen {
+					add(name+"_bucket", pm, float64(h.GetSampleCount()), "le", "+Inf")
+				}
+				add(name+"_sum", pm, h.GetSampleSum())
+				add(name+"_count", pm, float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				s := pm.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add(name, pm, q.GetValue(), "quantile", strconv.FormatFloat(q.GetQuantile(), 'g', -1, 64))
+				}
+				add(name+"_sum", pm, s.GetSampleSum())
+				add(name+"_count", pm, float64(s.GetSampleCount()))
+			}
+		}
+	}
+	return buf, samples
+}
+
+// encodeTimeSeries encodes a time series with a single sample. labels holds
+// name/value pairs and is sorted by name, as the protocol requires.
+func encodeTimeSeries(labels []string, v float64, ts int64) []byte {
+	pairs := make([][2]string, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		pairs = append(pairs, [2]string{labels[i], labels[i+1]})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+
+	var buf []byte
+	for _, p := range pairs {
+		var label []byte
+		label = protowire.AppendTag(label, 1, protowire.BytesType)
+		label = protowire.AppendString(label, p[0])
+		label = protowire.AppendTag(label, 2, protowire.BytesType)
+		label = protowire.AppendString(label, p[1])
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, label)
+	}
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(v))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, uint64(ts))
+	buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+	return protowire.AppendBytes(buf, sample)
+}
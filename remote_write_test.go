@@ -0,0 +1,211 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeWriteRequest decodes a remote write request into its series, keyed
+// by their labels in text format.
+func decodeWriteRequest(t *testing.T, data []byte) map[string]float64 {
+	t.Helper()
+	series := map[string]float64{}
+	for _, ts := range decodeField(t, data, 1) {
+		var labels []string
+		for _, l := range decodeField(t, ts, 1) {
+			name, value := decodeField(t, l, 1), decodeField(t, l, 2)
+			labels = append(labels, string(name[0])+"="+string(value[0]))
+		}
+		sample := decodeField(t, ts, 2)
+		if len(sample) != 1 {
+			t.Fatalf("want a single sample, got %d", len(sample))
+		}
+		v, n := protowire.ConsumeFixed64(sample[0][1:])
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		series[strings.Join(labels, ",")] = math.Float64frombits(v)
+	}
+	return series
+}
+
+// decodeField returns the values of the bytes field num in a message.
+func decodeField(t *testing.T, msg []byte, num protowire.Number) [][]byte {
+	t.Helper()
+	var values [][]byte
+	for len(msg) > 0 {
+		n, typ, l := protowire.ConsumeTag(msg)
+		if l < 0 {
+			t.Fatal(protowire.ParseError(l))
+		}
+		msg = msg[l:]
+		if typ != protowire.BytesType {
+			l = protowire.ConsumeFieldValue(n, typ, msg)
+			msg = msg[l:]
+			continue
+		}
+		v, l := protowire.ConsumeBytes(msg)
+		if l < 0 {
+			t.Fatal(protowire.ParseError(l))
+		}
+		if n == num {
+			values = append(values, v)
+		}
+		msg = msg[l:]
+	}
+	return values
+}
+
+func TestRemoteWrite(t *testing.T) {
+	var (
+		status   atomic.Int32
+		received = make(chan map[string]float64, 10)
+	)
+	status.Store(http.StatusServiceUnavailable)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range map[string]string{
+			"Content-Encoding":                  "snappy",
+			"Content-Type":                      "application/x-protobuf",
+			"X-Prometheus-Remote-Write-Version": "0.1.0",
+			"Authorization":                     "Bearer secret",
+		} {
+			if got := r.Header.Get(k); got != v {
+				t.Errorf("want header %s %q, got %q", k, v, got)
+			}
+		}
+		if s := int(status.Load()); s != http.StatusOK {
+			http.Error(w, "unavailable", s)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		data, err := snappy.Decode(nil, body)
+		if err != nil {
+			t.Errorf("couldn't decompress request: %s", err)
+		}
+		received <- decodeWriteRequest(t, data)
+	}))
+	defer receiver.Close()
+
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_total", Help: "counter"}, []string{"device"})
+	counter.WithLabelValues("eth0").Add(3)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Help: "histogram", Buckets: []float64{1}})
+	histogram.Observe(0.5)
+	histogram.Observe(2)
+	reg.MustRegister(counter, histogram)
+
+	dir := t.TempDir()
+	newWriter := func() *remoteWriter {
+		w := &remoteWriter{
+			url:     receiver.URL,
+			headers: map[string]string{"Authorization": "Bearer secret"},
+			timeout: time.Second,
+			maxAge:  time.Hour,
+			dir:     dir,
+			gather:  func(context.Context) ([]*dto.MetricFamily, error) { return reg.Gather() },
+			client:  receiver.Client(),
+			logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}
+		if err := w.loadBuffer(); err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+
+	// An unavailable endpoint leaves the batch buffered on disk.
+	sent := testutil.ToFloat64(remoteWriteSamplesSent)
+	w := newWriter()
+	w.enqueue(context.Background())
+	if err := w.flush(context.Background()); err == nil {
+		t.Fatal("expected error on HTTP 503")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("want one buffered batch, got %d files", len(files))
+	}
+	if want, got := 5.0, testutil.ToFloat64(remoteWritePendingSamples); want != got {
+		t.Errorf("want %v pending samples, got %v", want, got)
+	}
+
+	// The buffered batch survives a restart and is sent once the endpoint
+	// is back.
+	status.Store(http.StatusOK)
+	w = newWriter()
+	if err := w.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	series := <-received
+	for k, v := range map[string]float64{
+		"__name__=test_total,device=eth0":      3,
+		"__name__=test_seconds_bucket,le=1":    1,
+		"__name__=test_seconds_bucket,le=+Inf": 2,
+		"__name__=test_seconds_sum":            2.5,
+		"__name__=test_seconds_count":          2,
+	} {
+		if got, ok := series[k]; !ok || got != v {
+			t.Errorf("want series %s %v, got %v", k, v, series)
+		}
+	}
+	if want, got := sent+5, testutil.ToFloat64(remoteWriteSamplesSent); want != got {
+		t.Errorf("want %v samples sent, got %v", want, got)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("want an empty buffer, got %d files", len(files))
+	}
+
+	// Batches rejected by the endpoint are dropped rather than retried.
+	status.Store(http.StatusBadRequest)
+	rejected := testutil.ToFloat64(remoteWriteSamplesDropped.WithLabelValues("rejected"))
+	w.enqueue(context.Background())
+	if err := w.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := rejected+5, testutil.ToFloat64(remoteWriteSamplesDropped.WithLabelValues("rejected")); want != got {
+		t.Errorf("want %v samples rejected, got %v", want, got)
+	}
+
+	// Batches older than the maximum age are dropped without being sent.
+	status.Store(http.StatusServiceUnavailable)
+	expired := testutil.ToFloat64(remoteWriteSamplesDropped.WithLabelValues("expired"))
+	w.enqueue(context.Background())
+	w.pending[0].created = time.Now().Add(-2 * time.Hour)
+	if err := w.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := expired+5, testutil.ToFloat64(remoteWriteSamplesDropped.WithLabelValues("expired")); want != got {
+		t.Errorf("want %v samples expired, got %v", want, got)
+	}
+	if len(w.pending) != 0 {
+		t.Errorf("want no pending batches, got %d", len(w.pending))
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxFileSize caps the content captured from a single file.
+const maxFileSize = 64 << 20
+
+// runCapture runs the node_exporter collect command under ptrace and writes
+// the files of the procfs, sysfs and udev data directories it read to a ttar
+// archive.
+func runCapture(args []string) error {
+	flags := flag.NewFlagSet("capture", flag.ExitOnError)
+	var (
+		exporter = flags.String("exporter", "./node_exporter", "node_exporter binary to run.")
+		output   = flags.String("output", "snapshot.ttar", "ttar archive to write.")
+		scrub    = flags.Bool("scrub", false, "Replace MAC addresses, serial numbers and unique identifiers with placeholders.")
+		procfs   = flags.String("path.procfs", "/proc", "procfs mountpoint.")
+		sysfs    = flags.String("path.sysfs", "/sys", "sysfs mountpoint.")
+		udevData = flags.String("path.udev.data", "/run/udev/data", "udev data path.")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: capture [flags] [-- <node_exporter flags>]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	collectArgs := append([]string{
+		"collect",
+		"--path.procfs=" + *procfs,
+		"--path.sysfs=" + *sysfs,
+		"--path.udev.data=" + *udevData,
+	}, flags.Args()...)
+	paths, exitCode, err := tracedPaths(*exporter, collectArgs...)
+	if err != nil {
+		return fmt.Errorf("couldn't trace %s: %w", *exporter, err)
+	}
+	if exitCode != 0 {
+		fmt.Fprintf(os.Stderr, "warning: %s exited with %d, the snapshot may be incomplete\n", *exporter, exitCode)
+	}
+
+	var roots []captureRoot
+	for _, r := range []captureRoot{{*procfs, "proc"}, {*sysfs, "sys"}, {*udevData, "udev/data"}} {
+		host, err := filepath.Abs(r.host)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, captureRoot{host: host, archive: r.archive})
+	}
+	s := newSnapshot(roots, func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+	})
+	for _, p := range slices.Sorted(maps.Keys(paths)) {
+		s.add(p)
+	}
+	if *scrub {
+		s.scrub()
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	comment := fmt.Sprintf("Archive created by tools capture from %s %s", *exporter, strings.Join(collectArgs, " "))
+	if err := s.writeTTAR(f, comment); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d entries to %s\n", len(s.entries), *output)
+	return nil
+}
+
+// captureRoot maps a directory of the host, e.g. /sys, to its location in
+// the archive, e.g. sys.
+type captureRoot struct {
+	host    string
+	archive string
+}
+
+// snapshot holds the files, directories and symlinks to archive, keyed by
+// archive path.
+type snapshot struct {
+	roots   []captureRoot
+	entries map[string]*entry
+	// warn reports files which couldn't be captured.
+	warn func(format string, args ...any)
+}
+
+type entry struct {
+	mode    fs.FileMode
+	content []byte
+	target  string
+}
+
+func newSnapshot(roots []captureRoot, warn func(string, ...any)) *snapshot {
+	return &snapshot{roots: roots, entries: map[string]*entry{}, warn: warn}
+}
+
+// locate returns the root a host path is under and the path relative to it.
+func (s *snapshot) locate(path string) (captureRoot, string, bool) {
+	path = filepath.Clean(path)
+	for _, r := range s.roots {
+		if rel, err := filepath.Rel(r.host, path); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return r, rel, true
+		}
+	}
+	return captureRoot{}, "", false
+}
+
+// add captures the host path and everything leading to it, following
+// symlinks. Paths outside of the roots are ignored.
+func (s *snapshot) add(path string) {
+	s.addDepth(path, 0)
+}
+
+func (s *snapshot) addDepth(path string, depth int) {
+	if depth > 40 {
+		s.warn("too many levels of symlinks: %s", path)
+		return
+	}
+	root, rel, ok := s.locate(path)
+	if !ok {
+		return
+	}
+	if fi, err := os.Stat(root.host); err == nil {
+		s.entries[root.archive] = &entry{mode: fi.Mode()}
+	}
+	if rel == "." {
+		s.addDir(root, root.host)
+		return
+	}
+	parts := strings.Split(rel, "/")
+	current := root.host
+	for i, part := range parts {
+		current = filepath.Join(current, part)
+		fi, err := os.Lstat(current)
+		if err != nil {
+			// Gone since it was read, or never existed.
+			return
+		}
+		archivePath := s.archivePath(root, current)
+		switch {
+		case fi.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(current)
+			if err != nil {
+				s.warn("couldn't read symlink %s: %s", current, err)
+				return
+			}
+			resolved := target
+			if !filepath.IsAbs(target) {
+				resolved = filepath.Join(filepath.Dir(current), target)
+			}
+			s.entries[archivePath] = &entry{mode: fi.Mode(), target: s.linkTarget(root, current, target)}
+			s.addDepth(filepath.Join(append([]string{resolved}, parts[i+1:]...)...), depth+1)
+			return
+		case fi.IsDir():
+			s.entries[archivePath] = &entry{mode: fi.Mode()}
+			if i == len(parts)-1 {
+				s.addDir(root, current)
+			}
+		case fi.Mode().IsRegular() && i == len(parts)-1:
+			if _, ok := s.entries[archivePath]; ok {
+				return
+			}
+			content, err := readFile(current)
+			if err != nil {
+				s.warn("couldn't read %s: %s", current, err)
+				return
+			}
+			s.entries[archivePath] = &entry{mode: fi.Mode(), content: content}
+		default:
+			return
+		}
+	}
+}
+
+// addDir captures the subdirectories and symlinks of a directory read by a
+// collector, so that its listing still finds them. Files are only captured
+// when read.
+func (s *snapshot) addDir(root captureRoot, dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		s.warn("couldn't list %s: %s", dir, err)
+		return
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		archivePath := s.archivePath(root, path)
+		if _, ok := s.entries[archivePath]; ok {
+			continue
+		}
+		switch {
+		case e.Type()&fs.ModeSymlink != 0:
+			if target, err := os.Readlink(path); err == nil {
+				s.entries[archivePath] = &entry{mode: fs.ModeSymlink | 0o777, target: s.linkTarget(root, path, target)}
+			}
+		case e.IsDir():
+			if fi, err := e.Info(); err == nil {
+				s.entries[archivePath] = &entry{mode: fi.Mode()}
+			}
+		}
+	}
+}
+
+// linkTarget returns the target of a symlink to archive. Absolute targets
+// within the same root are made relative, to keep the archive
+// self-contained.
+func (s *snapshot) linkTarget(root captureRoot, link, target string) string {
+	if !filepath.IsAbs(target) {
+		return target
+	}
+	if targetRoot, _, ok := s.locate(target); ok && targetRoot == root {
+		if rel, err := filepath.Rel(filepath.Dir(link), target); err == nil {
+			return rel
+		}
+	}
+	return target
+}
+
+func (s *snapshot) archivePath(root captureRoot, path string) string {
+	rel, _ := filepath.Rel(root.host, path)
+	return filepath.ToSlash(filepath.Join(root.archive, rel))
+}
+
+// readFile reads a file of a pseudo filesystem, whose size isn't known
+// upfront.
+func readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, maxFileSize))
+}
+
+var (
+	// macRE matches MAC addresses, including the 20 byte ones of
+	// InfiniBand.
+	macRE = regexp.MustCompile(`\b[0-9a-fA-F]{2}(?::[0-9a-fA-F]{2}){5,19}\b`)
+	// serialFiles are the names of sysfs files holding serial numbers and
+	// other unique identifiers.
+	serialFiles = []string{"serial", "serial_number", "board_serial", "chassis_serial", "product_serial", "product_uuid", "wwid", "unique_id"}
+	// serialProperties are the udev properties holding them.
+	serialProperties = regexp.MustCompile(`(?m)^E:ID_(?:SERIAL|SERIAL_SHORT|SCSI_SERIAL|WWN|WWN_WITH_EXTENSION|FS_UUID|FS_UUID_ENC|PART_TABLE_UUID|PART_ENTRY_UUID)=(.+)$`)
+)
+
+// scrub replaces MAC addresses, serial numbers and unique identifiers in all
+// files with placeholders. Each value is replaced by the same placeholder
+// everywhere, so that references between files still match.
+func (s *snapshot) scrub() {
+	paths := slices.Sorted(maps.Keys(s.entries))
+	replacements := map[string]string{}
+	add := func(value, replacement string) {
+		if _, ok := replacements[value]; ok || value == "" {
+			return
+		}
+		replacements[value] = replacement
+	}
+	var macs, serials int
+	for _, p := range paths {
+		content := s.entries[p].content
+		for _, mac := range macRE.FindAll(content, -1) {
+			m := strings.ToLower(string(mac))
+			if strings.Trim(m, "0:") == "" || strings.Trim(m, "f:") == "" {
+				// All zeroes or broadcast, nothing to hide.
+				continue
+			}
+			if _, ok := replacements[string(mac)]; ok {
+				continue
+			}
+			macs++
+			octets := make([]string, strings.Count(m, ":")+1)
+			for i := range octets {
+				octets[i] = "00"
+			}
+			octets[0] = "02"
+			for i, n := len(octets)-1, macs; n > 0 && i > 0; i, n = i-1, n>>8 {
+				octets[i] = fmt.Sprintf("%02x", n&0xff)
+			}
+			add(string(mac), strings.Join(octets, ":"))
+		}
+		var values [][]byte
+		if slices.Contains(serialFiles, filepath.Base(p)) {
+			values = append(values, bytes.TrimSpace(content))
+		}
+		for _, m := range serialProperties.FindAllSubmatch(content, -1) {
+			values = append(values, bytes.TrimSpace(m[1]))
+		}
+		for _, v := range values {
+			if _, ok := replacements[string(v)]; ok || len(v) == 0 {
+				continue
+			}
+			serials++
+			add(string(v), fmt.Sprintf("SCRUBBED%d", serials))
+		}
+	}
+
+	// Replace longer values first, in case one contains another.
+	values := make([]string, 0, len(replacements))
+	for v := range replacements {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	pairs := make([]string, 0, 2*len(values))
+	for _, v := range values {
+		pairs = append(pairs, v, replacements[v])
+	}
+	replacer := strings.NewReplacer(pairs...)
+	for _, p := range paths {
+		e := s.entries[p]
+		if e.content != nil {
+			e.content = []byte(replacer.Replace(string(e.content)))
+		}
+		if e.target != "" {
+			e.target = replacer.Replace(e.target)
+		}
+	}
+}
+
+// writeTTAR writes the snapshot in the format of the ttar script.
+func (s *snapshot) writeTTAR(w io.Writer, comment string) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n", comment)
+	for _, p := range slices.Sorted(maps.Keys(s.entries)) {
+		e := s.entries[p]
+		switch {
+		case e.mode&fs.ModeSymlink != 0:
+			fmt.Fprintf(&b, "Path: %s\nSymlinkTo: %s\n", p, e.target)
+		case e.mode.IsDir():
+			fmt.Fprintf(&b, "Directory: %s\nMode: %o\n", p, e.mode.Perm())
+		default:
+			content := e.content
+			lines := bytes.Count(content, []byte("\n"))
+			eofWithoutNewline := len(content) > 0 && content[len(content)-1] != '\n'
+			if eofWithoutNewline {
+				lines++
+			}
+			fmt.Fprintf(&b, "Path: %s\nLines: %d\n", p, lines)
+			content = bytes.ReplaceAll(content, []byte("EOF"), []byte(`\EOF`))
+			content = bytes.ReplaceAll(content, []byte("NULLBYTE"), []byte(`\NULLBYTE`))
+			content = bytes.ReplaceAll(content, []byte("\x00"), []byte("NULLBYTE"))
+			b.Write(content)
+			if eofWithoutNewline {
+				b.WriteString("EOF\n")
+			}
+			fmt.Fprintf(&b, "Mode: %o\n", e.mode.Perm())
+		}
+		b.WriteString("# ttar - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -\n")
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
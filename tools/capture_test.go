@@ -0,0 +1,271 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testRoots = []captureRoot{
+	{host: "/host/sys", archive: "sys"},
+	{host: "/proc", archive: "proc"},
+	{host: "/run/udev/data", archive: "udev/data"},
+}
+
+func noWarn(t *testing.T) func(string, ...any) {
+	return func(format string, args ...any) {
+		t.Errorf("unexpected warning: "+format, args...)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	s := newSnapshot(testRoots, noWarn(t))
+	for _, tc := range []struct {
+		path    string
+		archive string
+		rel     string
+		ok      bool
+	}{
+		{path: "/host/sys/class/net/eth0/mtu", archive: "sys", rel: "class/net/eth0/mtu", ok: true},
+		{path: "/host/sys", archive: "sys", rel: ".", ok: true},
+		{path: "/host/sys/", archive: "sys", rel: ".", ok: true},
+		{path: "/proc/1/../stat", archive: "proc", rel: "stat", ok: true},
+		{path: "/run/udev/data/b8:0", archive: "udev/data", rel: "b8:0", ok: true},
+		{path: "/host/system", ok: false},
+		{path: "/host/sys/../proc/stat", ok: false},
+		{path: "/etc/passwd", ok: false},
+		{path: "/run/udev", ok: false},
+	} {
+		root, rel, ok := s.locate(tc.path)
+		if ok != tc.ok || root.archive != tc.archive || rel != tc.rel {
+			t.Errorf("%s: want %q, %q, %t, got %q, %q, %t", tc.path, tc.archive, tc.rel, tc.ok, root.archive, rel, ok)
+		}
+	}
+}
+
+func TestLinkTarget(t *testing.T) {
+	s := newSnapshot(testRoots, noWarn(t))
+	sys := testRoots[0]
+	for _, tc := range []struct {
+		link   string
+		target string
+		want   string
+	}{
+		{link: "/host/sys/class/net/eth0", target: "../../devices/pci0000:00/net/eth0", want: "../../devices/pci0000:00/net/eth0"},
+		{link: "/host/sys/class/net/eth0", target: "/host/sys/devices/pci0000:00/net/eth0", want: "../../devices/pci0000:00/net/eth0"},
+		{link: "/host/sys/block/sda", target: "/host/sys/block/sdb", want: "sdb"},
+		// Absolute targets out of the root are kept as is.
+		{link: "/host/sys/class/net/eth0", target: "/proc/net/dev", want: "/proc/net/dev"},
+		{link: "/host/sys/class/net/eth0", target: "/dev/null", want: "/dev/null"},
+	} {
+		if got := s.linkTarget(sys, tc.link, tc.target); got != tc.want {
+			t.Errorf("%s -> %s: want %q, got %q", tc.link, tc.target, tc.want, got)
+		}
+	}
+}
+
+func TestArchivePath(t *testing.T) {
+	s := newSnapshot(testRoots, noWarn(t))
+	for _, tc := range []struct {
+		root captureRoot
+		path string
+		want string
+	}{
+		{root: testRoots[0], path: "/host/sys", want: "sys"},
+		{root: testRoots[0], path: "/host/sys/class/net", want: "sys/class/net"},
+		{root: testRoots[2], path: "/run/udev/data/b8:0", want: "udev/data/b8:0"},
+	} {
+		if got := s.archivePath(tc.root, tc.path); got != tc.want {
+			t.Errorf("%s: want %q, got %q", tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestScrub(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		entries map[string]*entry
+		want    map[string]string
+	}{
+		{
+			name: "mac addresses",
+			entries: map[string]*entry{
+				"sys/class/net/eth0/address":   {content: []byte("52:54:00:12:34:56\n")},
+				"sys/class/net/eth0/broadcast": {content: []byte("ff:ff:ff:ff:ff:ff\n")},
+				"sys/class/net/lo/address":     {content: []byte("00:00:00:00:00:00\n")},
+				"sys/class/net/eth1/address":   {content: []byte("52:54:00:AB:CD:EF\n")},
+				"proc/net/arp":                 {content: []byte("10.0.0.1 0x1 0x2 52:54:00:12:34:56 * eth0\n")},
+			},
+			want: map[string]string{
+				"sys/class/net/eth0/address":   "02:00:00:00:00:01\n",
+				"sys/class/net/eth0/broadcast": "ff:ff:ff:ff:ff:ff\n",
+				"sys/class/net/lo/address":     "00:00:00:00:00:00\n",
+				"sys/class/net/eth1/address":   "02:00:00:00:00:02\n",
+				"proc/net/arp":                 "10.0.0.1 0x1 0x2 02:00:00:00:00:01 * eth0\n",
+			},
+		},
+		{
+			name: "infiniband address",
+			entries: map[string]*entry{
+				"sys/class/net/ib0/address": {content: []byte("80:00:02:08:fe:80:00:00:00:00:00:00:00:02:c9:03:00:0a:b1:c2\n")},
+			},
+			want: map[string]string{
+				"sys/class/net/ib0/address": "02:00:00:00:00:00:00:00:00:00:00:00:00:00:00:00:00:00:00:01\n",
+			},
+		},
+		{
+			name: "serial numbers",
+			entries: map[string]*entry{
+				"sys/class/dmi/id/product_serial": {content: []byte("ABC123\n")},
+				"sys/block/sda/device/wwid":       {content: []byte("naa.5000c500a1b2c3d4\n")},
+				"sys/class/dmi/id/board_serial":   {content: []byte("\n")},
+				"udev/data/b8:0":                  {content: []byte("E:ID_SERIAL=Disk_ABC123\nE:ID_SERIAL_SHORT=ABC123\nE:ID_MODEL=Disk\n")},
+				"sys/class/block/sda":             {mode: fs.ModeSymlink, target: "../../devices/ABC123/block/sda"},
+			},
+			want: map[string]string{
+				"sys/class/dmi/id/product_serial": "SCRUBBED2\n",
+				"sys/block/sda/device/wwid":       "SCRUBBED1\n",
+				"sys/class/dmi/id/board_serial":   "\n",
+				// The longest value is replaced first.
+				"udev/data/b8:0":      "E:ID_SERIAL=SCRUBBED3\nE:ID_SERIAL_SHORT=SCRUBBED2\nE:ID_MODEL=Disk\n",
+				"sys/class/block/sda": "../../devices/SCRUBBED2/block/sda",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newSnapshot(testRoots, noWarn(t))
+			s.entries = tc.entries
+			s.scrub()
+			for path, want := range tc.want {
+				e := s.entries[path]
+				got := string(e.content)
+				if e.mode&fs.ModeSymlink != 0 {
+					got = e.target
+				}
+				if got != want {
+					t.Errorf("%s: want %q, got %q", path, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteTTAR(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		entry *entry
+		want  string
+	}{
+		{
+			name:  "directory",
+			entry: &entry{mode: fs.ModeDir | 0o755},
+			want:  "Directory: a\nMode: 755\n",
+		},
+		{
+			name:  "symlink",
+			entry: &entry{mode: fs.ModeSymlink | 0o777, target: "../b"},
+			want:  "Path: a\nSymlinkTo: ../b\n",
+		},
+		{
+			name:  "file",
+			entry: &entry{mode: 0o644, content: []byte("1\n2\n")},
+			want:  "Path: a\nLines: 2\n1\n2\nMode: 644\n",
+		},
+		{
+			name:  "empty file",
+			entry: &entry{mode: 0o444},
+			want:  "Path: a\nLines: 0\nMode: 444\n",
+		},
+		{
+			name:  "no trailing newline",
+			entry: &entry{mode: 0o644, content: []byte("1\n2")},
+			want:  "Path: a\nLines: 2\n1\n2EOF\nMode: 644\n",
+		},
+		{
+			name:  "escapes",
+			entry: &entry{mode: 0o644, content: []byte("EOF\x00NULLBYTE\n")},
+			want:  "Path: a\nLines: 1\n\\EOFNULLBYTE\\NULLBYTE\nMode: 644\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newSnapshot(testRoots, noWarn(t))
+			s.entries["a"] = tc.entry
+			var b bytes.Buffer
+			if err := s.writeTTAR(&b, "comment"); err != nil {
+				t.Fatal(err)
+			}
+			want := "# comment\n" + tc.want + "# ttar - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -\n"
+			if got := b.String(); got != want {
+				t.Errorf("want %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	dir := t.TempDir()
+	sys := filepath.Join(dir, "sys")
+	for _, d := range []string{"devices/pci0/net/eth0/queues", "class/net"} {
+		if err := os.MkdirAll(filepath.Join(sys, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"devices/pci0/net/eth0/mtu", "devices/pci0/net/eth0/speed"} {
+		if err := os.WriteFile(filepath.Join(sys, f), []byte("1500\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(sys, "devices/pci0/net/eth0"), filepath.Join(sys, "class/net/eth0")); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newSnapshot([]captureRoot{{host: sys, archive: "sys"}}, noWarn(t))
+	s.add(filepath.Join(sys, "class/net"))
+	s.add(filepath.Join(sys, "class/net/eth0/mtu"))
+	s.add(filepath.Join(sys, "devices/pci0/net/eth0"))
+	s.add(filepath.Join(dir, "other"))
+
+	for path, want := range map[string]string{
+		"sys":                           "dir",
+		"sys/class/net":                 "dir",
+		"sys/class/net/eth0":            "../../devices/pci0/net/eth0",
+		"sys/devices/pci0/net/eth0":     "dir",
+		"sys/devices/pci0/net/eth0/mtu": "1500\n",
+		// Listed directories are captured, but files only when read.
+		"sys/devices/pci0/net/eth0/queues": "dir",
+		"sys/devices/pci0/net/eth0/speed":  "",
+	} {
+		e, ok := s.entries[path]
+		var got string
+		switch {
+		case !ok:
+		case e.mode.IsDir():
+			got = "dir"
+		case e.mode&fs.ModeSymlink != 0:
+			got = e.target
+		default:
+			got = string(e.content)
+		}
+		if got != want {
+			t.Errorf("%s: want %q, got %q", path, want, got)
+		}
+	}
+	if len(s.entries) != 10 {
+		t.Errorf("want 10 entries, got %d", len(s.entries))
+	}
+}
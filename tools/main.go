@@ -25,7 +25,11 @@ import (
 func main() {
 	printHelpAndDie := func() {
 		fmt.Println(`
-Usage: tools [command]`)
+Usage: tools [command]
+
+Commands:
+  match <file>  Exit with 0 if the file is built for the host, 1 otherwise.
+  capture       Capture the files read by the collectors into a ttar archive.`)
 		os.Exit(1)
 	}
 	if len(os.Args) < 2 {
@@ -73,6 +77,11 @@ Usage: tools [command]`)
 			os.Exit(0)
 		}
 		os.Exit(1)
+	case "capture":
+		if err := runCapture(os.Args[2:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
 	default:
 		printHelpAndDie()
 	}
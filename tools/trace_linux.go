@@ -0,0 +1,192 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux && (amd64 || arm64)
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// tracedPaths runs a command under ptrace and returns the paths passed to
+// the system calls opening, stat'ing or reading the link of a file, by the
+// command and all its threads and children, along with the command's exit
+// code.
+func tracedPaths(name string, args ...string) (map[string]bool, int, error) {
+	// All ptrace requests have to come from the thread which started the
+	// tracee.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer devNull.Close()
+	// Files, so that no goroutines copying the output are started, which
+	// would only finish in cmd.Wait.
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = devNull
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Ptrace: true}
+	if err := cmd.Start(); err != nil {
+		return nil, 0, err
+	}
+	pid := cmd.Process.Pid
+
+	// The tracee stops with SIGTRAP on exec.
+	var ws unix.WaitStatus
+	if _, err := unix.Wait4(pid, &ws, unix.WALL, nil); err != nil {
+		return nil, 0, err
+	}
+	if !ws.Stopped() {
+		return nil, 0, fmt.Errorf("%s didn't stop after exec: %v", name, ws)
+	}
+	options := unix.PTRACE_O_TRACESYSGOOD | unix.PTRACE_O_TRACECLONE | unix.PTRACE_O_TRACEFORK |
+		unix.PTRACE_O_TRACEVFORK | unix.PTRACE_O_EXITKILL
+	if err := unix.PtraceSetOptions(pid, options); err != nil {
+		return nil, 0, fmt.Errorf("couldn't set ptrace options: %w", err)
+	}
+	if err := unix.PtraceSyscall(pid, 0); err != nil {
+		return nil, 0, err
+	}
+
+	paths := map[string]bool{}
+	stops := syscallStops{}
+	exitCode := -1
+	for {
+		tid, err := unix.Wait4(-1, &ws, unix.WALL, nil)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if errors.Is(err, unix.ECHILD) {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		switch {
+		case ws.Exited():
+			if tid == pid {
+				exitCode = ws.ExitStatus()
+			}
+			stops.gone(tid)
+			continue
+		case ws.Signaled():
+			stops.gone(tid)
+			continue
+		case !ws.Stopped():
+			continue
+		}
+		var signal unix.Signal
+		switch sig := ws.StopSignal(); sig {
+		case unix.SIGTRAP | 0x80:
+			// System call entry or exit. The arguments are only read at
+			// entry, the first argument register holding the return value
+			// at exit on some architectures.
+			if !stops.entering(tid) {
+				break
+			}
+			if path, ok := syscallPath(tid); ok {
+				paths[path] = true
+			}
+		case unix.SIGTRAP, unix.SIGSTOP:
+			// Events, e.g. a new thread, and the stop of new threads.
+		default:
+			signal = sig
+		}
+		// The thread may have been killed in the meantime.
+		_ = unix.PtraceSyscall(tid, int(signal))
+	}
+	return paths, exitCode, nil
+}
+
+// syscallStops tells the system call entry stops of the threads from the
+// exit ones, which ptrace reports alike: they alternate, starting with an
+// entry.
+type syscallStops map[int]bool
+
+// entering records a system call stop of the thread and returns whether it
+// is an entry.
+func (s syscallStops) entering(tid int) bool {
+	s[tid] = !s[tid]
+	return s[tid]
+}
+
+// gone forgets a thread which exited, as its ID can be reused.
+func (s syscallStops) gone(tid int) {
+	delete(s, tid)
+}
+
+// syscallPath returns the absolute path passed to the system call the
+// thread is stopped in, if it is one of pathSyscalls.
+func syscallPath(tid int) (string, bool) {
+	var regs unix.PtraceRegs
+	if err := unix.PtraceGetRegs(tid, &regs); err != nil {
+		return "", false
+	}
+	nr, args := syscallArgs(&regs)
+	arg, ok := pathSyscalls[nr]
+	if !ok {
+		return "", false
+	}
+	path, err := peekString(tid, uintptr(args[arg]))
+	if err != nil || path == "" {
+		return "", false
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), true
+	}
+	// Resolve relative paths against the directory file descriptor of the
+	// *at system calls, or else the working directory.
+	base := "cwd"
+	if arg == 1 && int32(args[0]) != unix.AT_FDCWD {
+		base = "fd/" + strconv.Itoa(int(int32(args[0])))
+	}
+	dir, err := os.Readlink(fmt.Sprintf("/proc/%d/%s", tid, base))
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(dir, path), true
+}
+
+// peekString reads a NUL-terminated string from the memory of the thread.
+func peekString(tid int, addr uintptr) (string, error) {
+	var (
+		s   []byte
+		buf [256]byte
+	)
+	for len(s) < unix.PathMax {
+		n, err := unix.PtracePeekData(tid, addr, buf[:])
+		if err != nil {
+			return "", err
+		}
+		if i := bytes.IndexByte(buf[:n], 0); i >= 0 {
+			return string(append(s, buf[:i]...)), nil
+		}
+		s = append(s, buf[:n]...)
+		addr += uintptr(n)
+	}
+	return "", errors.New("path too long")
+}
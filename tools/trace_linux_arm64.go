@@ -0,0 +1,30 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// pathSyscalls maps the system calls taking a path to the index of that
+// argument. For the *at ones, the first argument is the directory file
+// descriptor. Only the *at system calls exist on arm64.
+var pathSyscalls = map[uint64]int{
+	unix.SYS_OPENAT:     1,
+	unix.SYS_FSTATAT:    1,
+	unix.SYS_READLINKAT: 1,
+	unix.SYS_STATX:      1,
+}
+
+func syscallArgs(regs *unix.PtraceRegs) (uint64, [2]uint64) {
+	return regs.Regs[8], [2]uint64{regs.Regs[0], regs.Regs[1]}
+}
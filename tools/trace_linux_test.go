@@ -0,0 +1,45 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux && (amd64 || arm64)
+
+package main
+
+import "testing"
+
+func TestSyscallStops(t *testing.T) {
+	s := syscallStops{}
+	for i, tc := range []struct {
+		tid      int
+		gone     bool
+		entering bool
+	}{
+		{tid: 1, entering: true},
+		{tid: 2, entering: true},
+		{tid: 1, entering: false},
+		{tid: 1, entering: true},
+		{tid: 2, entering: false},
+		// A thread killed in a system call, whose ID is reused.
+		{tid: 1, gone: true},
+		{tid: 1, entering: true},
+		{tid: 1, entering: false},
+	} {
+		if tc.gone {
+			s.gone(tc.tid)
+			continue
+		}
+		if got := s.entering(tc.tid); got != tc.entering {
+			t.Errorf("%d: thread %d: want entering %t, got %t", i, tc.tid, tc.entering, got)
+		}
+	}
+}